@@ -1,20 +1,103 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/baj-/gum/internal/version"
 )
 
-var versionManager version.Manager = version.NewManager()
+var versionManager version.Manager
 
 func main() {
-	os.Exit(runCLI(os.Args, os.Stdout, os.Stderr))
+	args, flags := extractGlobalFlags(os.Args)
+	config, err := loadConfig(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if flags.offline {
+		config.Offline = true
+	}
+	if flags.insecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	versionManager, err = version.NewManager(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Cancel the running command on Ctrl-C or when asked to stop, so it
+	// gets to clean up after itself
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := runCLI(ctx, args, os.Stdout, os.Stderr)
+	// stop cancels ctx as well, so check for a signal before calling it
+	interrupted := ctx.Err() != nil
+	stop()
+
+	if interrupted {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(130)
+	}
+	os.Exit(code)
 }
 
-func runCLI(args []string, stdout, stderr io.Writer) int {
+// globalFlags are the flags that work with every command
+type globalFlags struct {
+	offline            bool
+	insecureSkipVerify bool
+}
+
+// loadConfig loads the config for the command in args. The config
+// command runs with the settings that could be read when the config
+// file is broken, and reports the problems itself, so the file can be
+// inspected and repaired with gum config.
+func loadConfig(args []string) (version.Config, error) {
+	config, err := version.LoadConfig()
+	if err != nil && len(args) > 1 && args[1] == "config" {
+		return config, nil
+	}
+	return config, err
+}
+
+// extractGlobalFlags removes the global flags from args, so they are
+// taken out before the command parses its own flags. Arguments after
+// "--" are left alone, and so are the arguments of shim-exec, which
+// all belong to the tool the shim runs.
+func extractGlobalFlags(args []string) ([]string, globalFlags) {
+	var rest []string
+	var flags globalFlags
+	for i, arg := range args {
+		switch {
+		case arg == "--", arg == "shim-exec" && len(rest) == 1:
+			return append(rest, args[i:]...), flags
+		case arg == "--offline", arg == "-offline":
+			flags.offline = true
+		case arg == "--insecure-skip-verify", arg == "-insecure-skip-verify":
+			flags.insecureSkipVerify = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, flags
+}
+
+func runCLI(ctx context.Context, args []string, stdout, stderr io.Writer) int {
 	if len(args) < 2 {
 		printUsage(stderr)
 		return 1
@@ -24,50 +107,303 @@ func runCLI(args []string, stdout, stderr io.Writer) int {
 
 	switch command {
 	case "install":
-		if len(args) < 3 {
+		var opts version.InstallOptions
+		flags := newFlagSet("install", stderr)
+		flags.BoolVar(&opts.Prerelease, "prerelease", false, "")
+		flags.IntVar(&opts.Parallel, "parallel", 4, "")
+		fromFile := flags.String("from-file", "", "")
+		versions, err := parseFlags(flags, args[2:])
+		if err != nil {
+			printUsage(stderr)
+			return 1
+		}
+
+		if *fromFile != "" {
+			listed, err := readVersionList(*fromFile)
+			if err != nil {
+				fmt.Fprintf(stderr, "Error reading versions: %v\n", err)
+				return 1
+			}
+			versions = append(versions, listed...)
+		}
+
+		if len(versions) < 1 || slices.ContainsFunc(versions, func(v string) bool { return strings.TrimSpace(v) == "" }) {
 			fmt.Fprintln(stderr, "Error: no version provided")
 			printUsage(stderr)
 			return 1
 		}
-		versionStr := args[2]
-		err := versionManager.Install(versionStr, stdout)
+		err = versionManager.InstallAll(ctx, versions, opts, stdout)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error installing Go %s: %v\n", versionStr, err)
+			fmt.Fprintf(stderr, "Error installing Go %s: %v\n", strings.Join(versions, ", "), err)
 			return 1
 		}
 		return 0
 	case "uninstall":
-		if len(args) < 3 {
+		if len(args) < 3 || strings.TrimSpace(args[2]) == "" {
 			fmt.Fprintln(stderr, "Error: no version provided")
 			printUsage(stderr)
 			return 1
 		}
 		versionStr := args[2]
-		err := versionManager.Uninstall(versionStr, stdout)
+		err := versionManager.Uninstall(ctx, versionStr, stdout)
 		if err != nil {
 			fmt.Fprintf(stderr, "Error uninstalling Go %s: %v\n", versionStr, err)
 			return 1
 		}
 		return 0
 	case "use":
+		var opts version.UseOptions
+		flags := newFlagSet("use", stderr)
+		flags.BoolVar(&opts.Install, "install", false, "")
+		positional, err := parseFlags(flags, args[2:])
+		if err != nil {
+			printUsage(stderr)
+			return 1
+		}
+
 		versionStr := ""
-		if len(args) >= 3 {
-			versionStr = args[2]
+		if len(positional) >= 1 {
+			versionStr = positional[0]
 		}
 
-		err := versionManager.Use(versionStr, stdout)
+		err = versionManager.Use(ctx, versionStr, opts, stdout)
 		if err != nil {
 			fmt.Fprintf(stderr, "Error setting Go %s as active: %v\n", versionStr, err)
 			return 1
 		}
 		return 0
 	case "list":
-		err := versionManager.List(stdout)
+		err := versionManager.List(ctx, stdout)
 		if err != nil {
 			fmt.Fprintf(stderr, "Error listing Go versions: %v\n", err)
 			return 1
 		}
 		return 0
+	case "pin":
+		if len(args) < 3 {
+			fmt.Fprintln(stderr, "Error: no version provided")
+			printUsage(stderr)
+			return 1
+		}
+		versionStr := args[2]
+		err := versionManager.Pin(ctx, versionStr, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error pinning Go %s: %v\n", versionStr, err)
+			return 1
+		}
+		return 0
+	case "env":
+		flags := newFlagSet("env", stderr)
+		shell := flags.String("shell", defaultShell(), "")
+		positional, err := parseFlags(flags, args[2:])
+		if err != nil {
+			printUsage(stderr)
+			return 1
+		}
+
+		versionStr := ""
+		if len(positional) >= 1 {
+			versionStr = positional[0]
+		}
+
+		err = versionManager.Env(ctx, versionStr, *shell, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error printing environment for Go %s: %v\n", versionStr, err)
+			return 1
+		}
+		return 0
+	case "hook":
+		if len(args) < 3 {
+			fmt.Fprintln(stderr, "Error: no shell provided")
+			printUsage(stderr)
+			return 1
+		}
+		err := version.Hook(args[2], stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error printing shell hook: %v\n", err)
+			return 1
+		}
+		return 0
+	case "shims":
+		if len(args) < 3 {
+			fmt.Fprintln(stderr, "Error: expected enable or disable")
+			printUsage(stderr)
+			return 1
+		}
+
+		var err error
+		switch args[2] {
+		case "enable":
+			err = versionManager.EnableShims(ctx, stdout)
+		case "disable":
+			err = versionManager.DisableShims(ctx, stdout)
+		default:
+			fmt.Fprintf(stderr, "Error: expected enable or disable, got %s\n", args[2])
+			printUsage(stderr)
+			return 1
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "Error updating shims: %v\n", err)
+			return 1
+		}
+		return 0
+	case "shim-exec":
+		// Called by the shims in ~/.gum/bin, not meant to be run directly
+		if len(args) < 3 {
+			fmt.Fprintln(stderr, "Error: no tool provided")
+			return 1
+		}
+
+		err := versionManager.ShimExec(ctx, args[2], args[3:], stderr)
+		if err != nil {
+			fmt.Fprintf(stderr, "gum: %v\n", err)
+			return 1
+		}
+		return 0
+	case "exec":
+		var opts version.ExecOptions
+		flags := newFlagSet("exec", stderr)
+		flags.BoolVar(&opts.Install, "install", false, "")
+		positional, err := parseFlags(flags, args[2:])
+		if err != nil {
+			printUsage(stderr)
+			return 1
+		}
+
+		if len(positional) < 1 || strings.TrimSpace(positional[0]) == "" {
+			fmt.Fprintln(stderr, "Error: no version provided")
+			printUsage(stderr)
+			return 1
+		}
+		if len(positional) < 2 {
+			fmt.Fprintln(stderr, "Error: no command provided")
+			printUsage(stderr)
+			return 1
+		}
+
+		versionStr := positional[0]
+		err = versionManager.Exec(ctx, versionStr, positional[1:], opts, stdout, stderr)
+
+		// The command reports its own errors, only its exit code is passed on
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			return exitErr.ExitCode()
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error running command under Go %s: %v\n", versionStr, err)
+			return 1
+		}
+		return 0
+	case "matrix":
+		var opts version.MatrixOptions
+		flags := newFlagSet("matrix", stderr)
+		flags.BoolVar(&opts.Supported, "supported", false, "")
+		flags.BoolVar(&opts.Install, "install", false, "")
+		flags.IntVar(&opts.Parallel, "parallel", 1, "")
+		flags.StringVar(&opts.LogDir, "log-dir", "", "")
+
+		// Versions come before "--", the command after it
+		sep := slices.Index(args, "--")
+		if sep < 0 {
+			fmt.Fprintln(stderr, "Error: expected -- before the command")
+			printUsage(stderr)
+			return 1
+		}
+
+		versions, err := parseFlags(flags, args[2:sep])
+		if err != nil {
+			printUsage(stderr)
+			return 1
+		}
+
+		err = versionManager.Matrix(ctx, versions, args[sep+1:], opts, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error running matrix: %v\n", err)
+			return 1
+		}
+		return 0
+	case "config":
+		if len(args) < 3 {
+			fmt.Fprintln(stderr, "Error: expected get, set or list")
+			printUsage(stderr)
+			return 1
+		}
+
+		var err error
+		switch {
+		case args[2] == "get" && len(args) == 4:
+			err = versionManager.ConfigGet(ctx, args[3], stdout)
+		case args[2] == "set" && len(args) == 5:
+			err = versionManager.ConfigSet(ctx, args[3], args[4], stdout)
+		case args[2] == "list" && len(args) == 3:
+			err = versionManager.ConfigList(ctx, stdout)
+		default:
+			fmt.Fprintf(stderr, "Error: invalid arguments for config %s\n", args[2])
+			printUsage(stderr)
+			return 1
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "Error accessing config: %v\n", err)
+			return 1
+		}
+		return 0
+	case "cache":
+		if len(args) < 3 {
+			fmt.Fprintln(stderr, "Error: expected list, size, clean or prune")
+			printUsage(stderr)
+			return 1
+		}
+
+		var err error
+		switch {
+		case args[2] == "list" && len(args) == 3:
+			err = versionManager.CacheList(ctx, stdout)
+		case args[2] == "size" && len(args) == 3:
+			err = versionManager.CacheSize(ctx, stdout)
+		case args[2] == "clean" && len(args) == 3:
+			err = versionManager.CacheClean(ctx, stdout)
+		case args[2] == "prune":
+			var olderThan string
+			flags := newFlagSet("cache prune", stderr)
+			flags.StringVar(&olderThan, "older-than", "", "")
+			positional, parseErr := parseFlags(flags, args[3:])
+			if parseErr != nil || len(positional) > 0 || olderThan == "" {
+				fmt.Fprintln(stderr, "Error: expected --older-than <age>")
+				printUsage(stderr)
+				return 1
+			}
+
+			age, parseErr := parseAge(olderThan)
+			if parseErr != nil {
+				fmt.Fprintf(stderr, "Error: %v\n", parseErr)
+				return 1
+			}
+			err = versionManager.CachePrune(ctx, age, stdout)
+		default:
+			fmt.Fprintf(stderr, "Error: invalid arguments for cache %s\n", args[2])
+			printUsage(stderr)
+			return 1
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "Error accessing cache: %v\n", err)
+			return 1
+		}
+		return 0
+	case "list-remote":
+		filter := ""
+		if len(args) >= 3 {
+			filter = args[2]
+		}
+
+		err := versionManager.ListRemote(ctx, filter, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error listing available Go versions: %v\n", err)
+			return 1
+		}
+		return 0
 	default:
 		fmt.Fprintf(stderr, "Unknown command: %s\n", command)
 		printUsage(stderr)
@@ -78,8 +414,112 @@ func runCLI(args []string, stdout, stderr io.Writer) int {
 func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "Go Utility Manager (gum)")
 	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  gum install <version>   - Install Go version")
-	fmt.Fprintln(w, "  gum uninstall <version> - Uninstall Go version")
-	fmt.Fprintln(w, "  gum use <version>       - Use Go version (uses go.mod if no version is provided)")
-	fmt.Fprintln(w, "  gum list                - List installed Go versions")
+	fmt.Fprintln(w, "  gum install <versions>     - Install Go versions")
+	fmt.Fprintln(w, "    --prerelease             - Allow major.minor versions to resolve to betas and release candidates")
+	fmt.Fprintln(w, "    --from-file <file>       - Also install the versions listed in a file, one per line")
+	fmt.Fprintln(w, "    --parallel <n>           - Number of versions to install at once (default 4)")
+	fmt.Fprintln(w, "  gum uninstall <version>    - Uninstall Go version")
+	fmt.Fprintln(w, "  gum use <version>          - Use Go version (detects the project's version if none is provided)")
+	fmt.Fprintln(w, "    --install                - Install the version first if it is missing (or set GUM_AUTO_INSTALL=1)")
+	fmt.Fprintln(w, "  gum pin <version>          - Pin Go version for the current directory in .gum-version")
+	fmt.Fprintln(w, "  gum env [version]          - Print shell exports using Go version in this shell only")
+	fmt.Fprintln(w, "    --shell <shell>          - Shell to print exports for: bash, zsh or fish")
+	fmt.Fprintln(w, "  gum hook <shell>           - Print shell hook switching Go version on every cd")
+	fmt.Fprintln(w, "  gum shims enable|disable   - Pick the Go version per invocation instead of globally")
+	fmt.Fprintln(w, "  gum exec <version> <cmd>   - Run a command using Go version, use -- before the command's flags")
+	fmt.Fprintln(w, "    --install                - Install the version first if it is missing")
+	fmt.Fprintln(w, "  gum matrix <versions>      - Run a command after -- using each Go version and summarise the results")
+	fmt.Fprintln(w, "    --supported              - Also use the Go releases currently supported upstream")
+	fmt.Fprintln(w, "    --install                - Install versions first if they are missing")
+	fmt.Fprintln(w, "    --parallel <n>           - Number of versions to run at once")
+	fmt.Fprintln(w, "    --log-dir <dir>          - Directory to write the output of each version to")
+	fmt.Fprintln(w, "  gum config get <key>       - Print a setting")
+	fmt.Fprintln(w, "  gum config set <key> <val> - Change a setting in the config file")
+	fmt.Fprintln(w, "  gum config list            - Print all settings")
+	fmt.Fprintln(w, "  gum cache list|size        - Show the downloaded archives kept for reuse")
+	fmt.Fprintln(w, "  gum cache clean            - Remove all downloaded archives")
+	fmt.Fprintln(w, "  gum cache prune            - Remove downloaded archives that were not used recently")
+	fmt.Fprintln(w, "    --older-than <age>       - Age such as 30d or 72h")
+	fmt.Fprintln(w, "  gum list                   - List installed Go versions")
+	fmt.Fprintln(w, "  gum list-remote [filter]   - List Go versions available for download (e.g. 1.23)")
+	fmt.Fprintln(w, "Flags for every command:")
+	fmt.Fprintln(w, "  --offline                  - Only use cached release lists and archives (or set GUM_OFFLINE=1)")
+	fmt.Fprintln(w, "  --insecure-skip-verify     - Do not verify TLS certificates, which lets anyone tamper with downloads")
+}
+
+// readVersionList reads the versions listed in the file at path, one
+// per line so constraints such as ">=1.22 <1.24" can contain spaces.
+// Text after # is a comment.
+func readVersionList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			versions = append(versions, line)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions listed in %s", path)
+	}
+	return versions, nil
+}
+
+// parseAge parses an age such as 72h, or a number of days such as 30d
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if age, err := time.ParseDuration(value); err == nil && age > 0 {
+		return age, nil
+	}
+	return 0, fmt.Errorf("expected an age such as 30d or 72h, got %q", value)
+}
+
+// defaultShell guesses the user's shell from $SHELL
+func defaultShell() string {
+	if shell := filepath.Base(os.Getenv("SHELL")); shell == "zsh" || shell == "fish" {
+		return shell
+	}
+	return "bash"
+}
+
+// newFlagSet creates a flag set for a command that reports errors to w
+func newFlagSet(name string, w io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(w)
+	flags.Usage = func() {}
+	return flags
+}
+
+// parseFlags parses flags anywhere among args and returns the remaining
+// positional arguments in order. Everything after "--" is positional.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		// Parsing stops at "--", so keep everything after it as is
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
 }
@@ -2,39 +2,153 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/baj-/gum/internal/version"
 )
 
 // MockVersionManager is a test implementation of version.Manager
 type MockVersionManager struct{}
 
-func (m *MockVersionManager) Install(version string, w io.Writer) error {
+func (m *MockVersionManager) Install(ctx context.Context, v string, opts version.InstallOptions, w io.Writer) error {
+	if opts.Prerelease {
+		fmt.Fprintln(w, "Including pre-releases")
+	}
 	// Just write the expected output to indicate we're mocking the functionality
-	_, err := fmt.Fprintf(w, "Downloading https://golang.org/dl/go%s\n", version)
+	_, err := fmt.Fprintf(w, "Downloading https://golang.org/dl/go%s\n", v)
 	return err
 }
 
-func (m *MockVersionManager) Uninstall(version string, w io.Writer) error {
+func (m *MockVersionManager) InstallAll(ctx context.Context, versions []string, opts version.InstallOptions, w io.Writer) error {
+	for _, v := range versions {
+		if err := m.Install(ctx, v, opts, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockVersionManager) Uninstall(ctx context.Context, version string, w io.Writer) error {
 	// Just write the expected output to indicate we're mocking the functionality
 	_, err := fmt.Fprintf(w, "Uninstalling Go version go%s\n", version)
 	return err
 }
 
-func (m *MockVersionManager) Use(version string, w io.Writer) error {
+func (m *MockVersionManager) Use(ctx context.Context, v string, opts version.UseOptions, w io.Writer) error {
+	if opts.Install {
+		fmt.Fprintf(w, "Installing Go go%s if missing\n", v)
+	}
 	// Just write the expected output to indicate we're mocking the functionality
-	_, err := fmt.Fprintf(w, "Setting Go go%s as active version\n", version)
+	_, err := fmt.Fprintf(w, "Setting Go go%s as active version\n", v)
 	return err
 }
 
-func (m *MockVersionManager) List(w io.Writer) error {
+func (m *MockVersionManager) List(ctx context.Context, w io.Writer) error {
 	// Just write some expected output
 	_, err := fmt.Fprintf(w, "Installed Go versions:\n  go1.24\n")
 	return err
 }
 
+func (m *MockVersionManager) ListRemote(ctx context.Context, filter string, w io.Writer) error {
+	// Just write some expected output
+	_, err := fmt.Fprintf(w, "Available Go versions matching %s:\n  go1.24.2\n", filter)
+	return err
+}
+
+func (m *MockVersionManager) Pin(ctx context.Context, v string, w io.Writer) error {
+	// Just write the expected output to indicate we're mocking the functionality
+	_, err := fmt.Fprintf(w, "Pinned Go %s\n", v)
+	return err
+}
+
+func (m *MockVersionManager) Env(ctx context.Context, v, shell string, w io.Writer) error {
+	// Just write some expected output
+	_, err := fmt.Fprintf(w, "export GUM_ENV_VERSION='go%s'; # %s\n", v, shell)
+	return err
+}
+
+func (m *MockVersionManager) EnableShims(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintln(w, "Enabled shims")
+	return err
+}
+
+func (m *MockVersionManager) DisableShims(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintln(w, "Disabled shims")
+	return err
+}
+
+func (m *MockVersionManager) ShimExec(ctx context.Context, tool string, args []string, stderr io.Writer) error {
+	return fmt.Errorf("cannot run %s %s", tool, strings.Join(args, " "))
+}
+
+func (m *MockVersionManager) Exec(ctx context.Context, v string, command []string, opts version.ExecOptions, stdout, stderr io.Writer) error {
+	// Exit with the requested code to check it is passed on
+	if command[0] == "exit" {
+		err := exec.Command("sh", "-c", "exit "+command[1]).Run()
+		return fmt.Errorf("%s failed under Go %s: %w", command[0], v, err)
+	}
+	if opts.Install {
+		fmt.Fprintf(stderr, "Installing Go go%s if missing\n", v)
+	}
+	_, err := fmt.Fprintf(stdout, "Running %s under Go go%s\n", strings.Join(command, " "), v)
+	return err
+}
+
+func (m *MockVersionManager) Matrix(ctx context.Context, versions, command []string, opts version.MatrixOptions, w io.Writer) error {
+	if opts.Supported {
+		versions = append(versions, "supported")
+	}
+	_, err := fmt.Fprintf(w, "Running %s with %s in %d parallel\n", strings.Join(command, " "), strings.Join(versions, ", "), opts.Parallel)
+	return err
+}
+
+func (m *MockVersionManager) ConfigGet(ctx context.Context, key string, w io.Writer) error {
+	if key != "install_dir" {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	_, err := fmt.Fprintln(w, "/mock/home/.gum/versions")
+	return err
+}
+
+func (m *MockVersionManager) ConfigSet(ctx context.Context, key, value string, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Set %s to %s\n", key, value)
+	return err
+}
+
+func (m *MockVersionManager) ConfigList(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintln(w, "install_dir = \"/mock/home/.gum/versions\"")
+	return err
+}
+
+func (m *MockVersionManager) CacheList(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintln(w, "Cached archives in /mock/home/.gum/cache:")
+	return err
+}
+
+func (m *MockVersionManager) CacheSize(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintln(w, "64.0 MB in 1 cached archives")
+	return err
+}
+
+func (m *MockVersionManager) CacheClean(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintln(w, "Removed 1 cached archives")
+	return err
+}
+
+func (m *MockVersionManager) CachePrune(ctx context.Context, olderThan time.Duration, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Removed archives older than %s\n", olderThan)
+	return err
+}
+
 func TestRunCLI(t *testing.T) {
 	// Save the original manager and restore it after tests
 	originalManager := versionManager
@@ -62,6 +176,30 @@ func TestRunCLI(t *testing.T) {
 			expectedOutput: "Downloading https://golang.org/dl/go1.24",
 			expectedCode:   0,
 		},
+		{
+			name:           "install prerelease",
+			args:           []string{"gum", "install", "1.26", "--prerelease"},
+			expectedOutput: "Including pre-releases",
+			expectedCode:   0,
+		},
+		{
+			name:           "install several versions",
+			args:           []string{"gum", "install", "--parallel", "2", "1.23", "1.24"},
+			expectedOutput: "Downloading https://golang.org/dl/go1.24",
+			expectedCode:   0,
+		},
+		{
+			name:         "install from missing file",
+			args:         []string{"gum", "install", "--from-file", "does-not-exist.txt"},
+			expectedErr:  "Error reading versions",
+			expectedCode: 1,
+		},
+		{
+			name:         "install unknown flag",
+			args:         []string{"gum", "install", "--bogus", "1.24"},
+			expectedErr:  "flag provided but not defined: -bogus",
+			expectedCode: 1,
+		},
 		{
 			name:         "install without version",
 			args:         []string{"gum", "install"},
@@ -86,18 +224,215 @@ func TestRunCLI(t *testing.T) {
 			expectedOutput: "Setting Go go1.24 as active version",
 			expectedCode:   0,
 		},
+		{
+			name:           "use with install",
+			args:           []string{"gum", "use", "--install", "1.24"},
+			expectedOutput: "Installing Go go1.24 if missing",
+			expectedCode:   0,
+		},
 		{
 			name:           "use without version",
 			args:           []string{"gum", "use"},
 			expectedOutput: "",
 			expectedCode:   0,
 		},
+		{
+			name:           "pin",
+			args:           []string{"gum", "pin", "1.24"},
+			expectedOutput: "Pinned Go 1.24",
+			expectedCode:   0,
+		},
+		{
+			name:         "pin without version",
+			args:         []string{"gum", "pin"},
+			expectedErr:  "Error: no version provided",
+			expectedCode: 1,
+		},
+		{
+			name:           "env",
+			args:           []string{"gum", "env", "--shell", "fish", "1.24"},
+			expectedOutput: "export GUM_ENV_VERSION='go1.24'; # fish",
+			expectedCode:   0,
+		},
+		{
+			name:           "hook",
+			args:           []string{"gum", "hook", "zsh"},
+			expectedOutput: "gum env --shell zsh",
+			expectedCode:   0,
+		},
+		{
+			name:         "hook unsupported shell",
+			args:         []string{"gum", "hook", "tcsh"},
+			expectedErr:  "unsupported shell",
+			expectedCode: 1,
+		},
+		{
+			name:         "hook without shell",
+			args:         []string{"gum", "hook"},
+			expectedErr:  "Error: no shell provided",
+			expectedCode: 1,
+		},
+		{
+			name:           "shims enable",
+			args:           []string{"gum", "shims", "enable"},
+			expectedOutput: "Enabled shims",
+			expectedCode:   0,
+		},
+		{
+			name:         "shims unknown action",
+			args:         []string{"gum", "shims", "toggle"},
+			expectedErr:  "expected enable or disable, got toggle",
+			expectedCode: 1,
+		},
+		{
+			name:         "shim exec failure",
+			args:         []string{"gum", "shim-exec", "go", "version"},
+			expectedErr:  "gum: cannot run go version",
+			expectedCode: 1,
+		},
+		{
+			name:           "exec",
+			args:           []string{"gum", "exec", "1.21", "--", "go", "test", "-v", "./..."},
+			expectedOutput: "Running go test -v ./... under Go go1.21",
+			expectedCode:   0,
+		},
+		{
+			name:         "exec with install",
+			args:         []string{"gum", "exec", "--install", "1.21", "--", "go", "version"},
+			expectedErr:  "Installing Go go1.21 if missing",
+			expectedCode: 0,
+		},
+		{
+			name:         "exec passes on exit code",
+			args:         []string{"gum", "exec", "1.21", "--", "exit", "3"},
+			expectedCode: 3,
+		},
+		{
+			name:         "install with empty version",
+			args:         []string{"gum", "install", "1.24", ""},
+			expectedErr:  "Error: no version provided",
+			expectedCode: 1,
+		},
+		{
+			name:         "uninstall with empty version",
+			args:         []string{"gum", "uninstall", " "},
+			expectedErr:  "Error: no version provided",
+			expectedCode: 1,
+		},
+		{
+			name:         "exec with empty version",
+			args:         []string{"gum", "exec", "", "go", "version"},
+			expectedErr:  "Error: no version provided",
+			expectedCode: 1,
+		},
+		{
+			name:         "exec without command",
+			args:         []string{"gum", "exec", "1.21"},
+			expectedErr:  "Error: no command provided",
+			expectedCode: 1,
+		},
+		{
+			name:           "matrix",
+			args:           []string{"gum", "matrix", "1.22", "1.23", "--parallel", "2", "--", "go", "test", "-v"},
+			expectedOutput: "Running go test -v with 1.22, 1.23 in 2 parallel",
+			expectedCode:   0,
+		},
+		{
+			name:           "matrix supported",
+			args:           []string{"gum", "matrix", "--supported", "--", "go", "vet"},
+			expectedOutput: "Running go vet with supported in 1 parallel",
+			expectedCode:   0,
+		},
+		{
+			name:         "matrix without separator",
+			args:         []string{"gum", "matrix", "1.22", "go", "test"},
+			expectedErr:  "Error: expected -- before the command",
+			expectedCode: 1,
+		},
+		{
+			name:           "config get",
+			args:           []string{"gum", "config", "get", "install_dir"},
+			expectedOutput: "/mock/home/.gum/versions",
+			expectedCode:   0,
+		},
+		{
+			name:         "config get unknown setting",
+			args:         []string{"gum", "config", "get", "colour"},
+			expectedErr:  `Error accessing config: unknown setting "colour"`,
+			expectedCode: 1,
+		},
+		{
+			name:           "config set",
+			args:           []string{"gum", "config", "set", "timeout", "30s"},
+			expectedOutput: "Set timeout to 30s",
+			expectedCode:   0,
+		},
+		{
+			name:         "config set without value",
+			args:         []string{"gum", "config", "set", "timeout"},
+			expectedErr:  "Error: invalid arguments for config set",
+			expectedCode: 1,
+		},
+		{
+			name:           "config list",
+			args:           []string{"gum", "config", "list"},
+			expectedOutput: `install_dir = "/mock/home/.gum/versions"`,
+			expectedCode:   0,
+		},
+		{
+			name:           "cache list",
+			args:           []string{"gum", "cache", "list"},
+			expectedOutput: "Cached archives in /mock/home/.gum/cache:",
+			expectedCode:   0,
+		},
+		{
+			name:           "cache size",
+			args:           []string{"gum", "cache", "size"},
+			expectedOutput: "64.0 MB in 1 cached archives",
+			expectedCode:   0,
+		},
+		{
+			name:           "cache clean",
+			args:           []string{"gum", "cache", "clean"},
+			expectedOutput: "Removed 1 cached archives",
+			expectedCode:   0,
+		},
+		{
+			name:           "cache prune",
+			args:           []string{"gum", "cache", "prune", "--older-than", "30d"},
+			expectedOutput: "Removed archives older than 720h0m0s",
+			expectedCode:   0,
+		},
+		{
+			name:         "cache prune without age",
+			args:         []string{"gum", "cache", "prune"},
+			expectedErr:  "Error: expected --older-than <age>",
+			expectedCode: 1,
+		},
+		{
+			name:         "cache prune invalid age",
+			args:         []string{"gum", "cache", "prune", "--older-than", "soon"},
+			expectedErr:  `Error: expected an age such as 30d or 72h, got "soon"`,
+			expectedCode: 1,
+		},
+		{
+			name:         "cache without subcommand",
+			args:         []string{"gum", "cache"},
+			expectedErr:  "Error: expected list, size, clean or prune",
+			expectedCode: 1,
+		},
 		{
 			name:           "list versions",
 			args:           []string{"gum", "list"},
 			expectedOutput: "Installed Go versions:",
 			expectedCode:   0,
 		},
+		{
+			name:           "list remote versions",
+			args:           []string{"gum", "list-remote", "1.24"},
+			expectedOutput: "Available Go versions matching 1.24:",
+			expectedCode:   0,
+		},
 		{
 			name:         "unknown command",
 			args:         []string{"gum", "llatsni"},
@@ -109,7 +444,7 @@ func TestRunCLI(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			var stdout, stderr bytes.Buffer
-			code := runCLI(tc.args, &stdout, &stderr)
+			code := runCLI(context.Background(), tc.args, &stdout, &stderr)
 
 			if code != tc.expectedCode {
 				t.Errorf("Expected exit code %d, got %d", tc.expectedCode, code)
@@ -133,7 +468,7 @@ func TestPrintUsage(t *testing.T) {
 	expected := []string{
 		"Go Utility Manager (gum)",
 		"Usage:",
-		"gum install <version>",
+		"gum install <versions>",
 		"gum uninstall <version>",
 		"gum use <version>",
 	}
@@ -144,3 +479,228 @@ func TestPrintUsage(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFlags(t *testing.T) {
+	testCases := []struct {
+		name               string
+		args               []string
+		expectedPositional []string
+		expectedFlag       bool
+	}{
+		{"flag before positional", []string{"--flag", "1.24"}, []string{"1.24"}, true},
+		{"flag after positional", []string{"1.24", "--flag"}, []string{"1.24"}, true},
+		{"no flag", []string{"1.23", "1.24"}, []string{"1.23", "1.24"}, false},
+		{"after double dash", []string{"1.24", "--", "go", "--flag"}, []string{"1.24", "go", "--flag"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			flags := newFlagSet("test", &stderr)
+			flagSet := flags.Bool("flag", false, "")
+
+			positional, err := parseFlags(flags, tc.args)
+			if err != nil {
+				t.Fatalf("parseFlags(%q) error = %v", tc.args, err)
+			}
+
+			if strings.Join(positional, " ") != strings.Join(tc.expectedPositional, " ") {
+				t.Errorf("parseFlags(%q) = %q, want %q", tc.args, positional, tc.expectedPositional)
+			}
+
+			if *flagSet != tc.expectedFlag {
+				t.Errorf("parseFlags(%q) flag = %v, want %v", tc.args, *flagSet, tc.expectedFlag)
+			}
+		})
+	}
+}
+
+func TestExtractGlobalFlags(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedArgs  []string
+		expectedFlags globalFlags
+	}{
+		{"no flags", []string{"gum", "install", "1.24"}, []string{"gum", "install", "1.24"}, globalFlags{}},
+		{"before command", []string{"gum", "--offline", "install", "1.24"}, []string{"gum", "install", "1.24"}, globalFlags{offline: true}},
+		{"after command", []string{"gum", "install", "1.24", "--insecure-skip-verify"}, []string{"gum", "install", "1.24"}, globalFlags{insecureSkipVerify: true}},
+		{"both", []string{"gum", "-offline", "list-remote", "--insecure-skip-verify"}, []string{"gum", "list-remote"}, globalFlags{offline: true, insecureSkipVerify: true}},
+		{"setting name", []string{"gum", "config", "get", "offline"}, []string{"gum", "config", "get", "offline"}, globalFlags{}},
+		{"after double dash", []string{"gum", "exec", "1.24", "--", "tool", "--offline"}, []string{"gum", "exec", "1.24", "--", "tool", "--offline"}, globalFlags{}},
+		{"shim arguments", []string{"gum", "shim-exec", "go", "run", ".", "--offline"}, []string{"gum", "shim-exec", "go", "run", ".", "--offline"}, globalFlags{}},
+		{"before shim-exec", []string{"gum", "--offline", "shim-exec", "go", "-insecure-skip-verify"}, []string{"gum", "shim-exec", "go", "-insecure-skip-verify"}, globalFlags{offline: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, flags := extractGlobalFlags(tc.args)
+
+			if strings.Join(args, " ") != strings.Join(tc.expectedArgs, " ") {
+				t.Errorf("extractGlobalFlags(%q) = %q, want %q", tc.args, args, tc.expectedArgs)
+			}
+
+			if flags != tc.expectedFlags {
+				t.Errorf("extractGlobalFlags(%q) flags = %+v, want %+v", tc.args, flags, tc.expectedFlags)
+			}
+		})
+	}
+}
+
+// writeBrokenConfig points GUM_CONFIG at a config file with a valid
+// install_dir and an invalid timeout
+func writeBrokenConfig(t *testing.T) string {
+	t.Helper()
+
+	installDir := filepath.Join(t.TempDir(), "versions")
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := fmt.Sprintf("install_dir = %q\ntimeout = \"soon\"\n", installDir)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("GUM_CONFIG", path)
+	t.Setenv("GUM_INSTALL_DIR", "")
+	t.Setenv("GUM_TIMEOUT", "")
+	return installDir
+}
+
+func TestLoadConfig(t *testing.T) {
+	installDir := writeBrokenConfig(t)
+
+	testCases := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"other command fails", []string{"gum", "install", "1.24"}, true},
+		{"config runs with what could be read", []string{"gum", "config", "set", "timeout", "1m"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := loadConfig(tc.args)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("loadConfig(%q) error = %v, wantErr %v", tc.args, err, tc.wantErr)
+			}
+			if err == nil && config.InstallDir != installDir {
+				t.Errorf("loadConfig(%q) InstallDir = %q, want %q", tc.args, config.InstallDir, installDir)
+			}
+		})
+	}
+}
+
+func TestRunCLIBrokenConfig(t *testing.T) {
+	installDir := writeBrokenConfig(t)
+
+	originalManager := versionManager
+	defer func() { versionManager = originalManager }()
+
+	testCases := []struct {
+		name           string
+		args           []string
+		expectedOutput string
+	}{
+		{"config list", []string{"gum", "config", "list"}, fmt.Sprintf("install_dir = %q\n", installDir)},
+		{"config get broken setting", []string{"gum", "config", "get", "timeout"}, "0s\n"},
+		{"config get", []string{"gum", "config", "get", "install_dir"}, installDir + "\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := loadConfig(tc.args)
+			if err != nil {
+				t.Fatalf("loadConfig(%q) error = %v", tc.args, err)
+			}
+			versionManager, err = version.NewManager(config)
+			if err != nil {
+				t.Fatalf("version.NewManager() error = %v", err)
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := runCLI(context.Background(), tc.args, &stdout, &stderr); code != 1 {
+				t.Errorf("Expected exit code 1, got %d", code)
+			}
+			if !strings.Contains(stdout.String(), tc.expectedOutput) {
+				t.Errorf("Expected output to contain %q, got %q", tc.expectedOutput, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), "invalid timeout") {
+				t.Errorf("Expected the broken setting to be reported, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestReadVersionList(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "one per line",
+			content: "1.22\n1.23.8\ngo1.24\n",
+			want:    []string{"1.22", "1.23.8", "go1.24"},
+		},
+		{
+			name:    "comments and blank lines",
+			content: "# Versions tested in CI\n\n1.23\n  1.24  # supported\n",
+			want:    []string{"1.23", "1.24"},
+		},
+		{
+			name:    "constraints",
+			content: ">=1.22 <1.24\n~1.21.3\r\n",
+			want:    []string{">=1.22 <1.24", "~1.21.3"},
+		},
+		{
+			name:    "empty",
+			content: "# nothing yet\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "versions.txt")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to write versions file: %v", err)
+			}
+
+			got, err := readVersionList(path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("readVersionList() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("readVersionList() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"72h", 72 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"1.5d", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseAge(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
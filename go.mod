@@ -1,3 +1,5 @@
 module github.com/baj-/gum
 
 go 1.24.2
+
+require golang.org/x/mod v0.33.0
@@ -0,0 +1,178 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Aliases name a version by what it is rather than by its number
+const (
+	// aliasLatest is the newest release, or pre-release if allowed
+	aliasLatest = "latest"
+	// aliasStable is the newest release marked stable
+	aliasStable = "stable"
+	// aliasOldstable is the newest release of the previous minor
+	// version, the other release supported upstream
+	aliasOldstable = "oldstable"
+	// aliasGoMod is the version the current project asks for
+	aliasGoMod = "go.mod"
+	// aliasInstalledLatest is the newest installed release
+	aliasInstalledLatest = "installed-latest"
+)
+
+// majorVersionRegex matches a bare major version, such as "1" for the
+// newest 1.x release
+var majorVersionRegex = regexp.MustCompile(`^\d+$`)
+
+// isMajorVersion checks if a version string names only a major version
+func isMajorVersion(v string) bool {
+	return majorVersionRegex.MatchString(v)
+}
+
+// isReleaseAlias reports whether v is an alias resolved from the
+// release list
+func isReleaseAlias(v string) bool {
+	return slices.Contains([]string{aliasLatest, aliasStable, aliasOldstable}, v)
+}
+
+// isReleaseSeries reports whether v names a release series, such as
+// 1 or 1.22, rather than a single release
+func isReleaseSeries(v string) bool {
+	series := strings.TrimPrefix(v, "go")
+	return isMajorMinorVersion(series) || isMajorVersion(series)
+}
+
+// resolveOptions controls how resolveSpec picks a version
+type resolveOptions struct {
+	// prerelease allows betas and release candidates
+	prerelease bool
+	// preferInstalled picks the newest installed version satisfying a
+	// constraint over newer ones available for download
+	preferInstalled bool
+}
+
+// resolveSpec turns the alias or version constraint v into the version
+// it currently stands for. latest, stable and oldstable are looked up
+// in the release list of src, go.mod is the version the project asks
+// for, and installed-latest the newest installed release. With
+// preferInstalled, a release series such as 1 or 1.22 is the newest
+// installed release of it, or else the newest available. A constraint
+// such as ">=1.22 <1.24" is resolved by resolveConstraint. Anything else
+// is returned as is.
+func (m *VersionManager) resolveSpec(ctx context.Context, v string, opts resolveOptions, src releaseSource, w io.Writer) (string, error) {
+	switch {
+	case isReleaseAlias(v):
+		resolved, err := resolveVersion(v, opts.prerelease, src)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve version %s: %w", v, err)
+		}
+		fmt.Fprintf(w, "Resolved %s to %s\n", v, resolved)
+		return resolved, nil
+
+	case v == aliasGoMod:
+		requested, err := detectVersionInGoMod(m.fs)
+		if err != nil {
+			return "", fmt.Errorf("failed to detect Go version: %w", err)
+		}
+		return m.resolveRequestedVersion(ctx, requested, w)
+
+	case v == aliasInstalledLatest:
+		installed, err := m.installedVersions()
+		if err != nil {
+			return "", err
+		}
+		for _, version := range installed {
+			if !isPrereleaseVersion(version) {
+				fmt.Fprintf(w, "Resolved %s to %s\n", v, version)
+				return version, nil
+			}
+		}
+		return "", fmt.Errorf("no Go versions installed yet")
+
+	case opts.preferInstalled && isReleaseSeries(v):
+		installed, err := m.newestInstalled(v)
+		if err != nil {
+			return "", err
+		}
+		if installed != "" {
+			fmt.Fprintf(w, "Resolved %s to installed %s\n", v, installed)
+			return installed, nil
+		}
+
+		resolved, err := resolveVersion(v, opts.prerelease, src)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve version %s: %w", v, err)
+		}
+		fmt.Fprintf(w, "Resolved %s to %s\n", v, resolved)
+		return resolved, nil
+
+	case isVersionConstraint(v):
+		constraint, err := parseVersionConstraint(v)
+		if err != nil {
+			return "", err
+		}
+		return m.resolveConstraint(constraint, opts, src, w)
+	}
+
+	return v, nil
+}
+
+// findLatestRelease returns the newest release in the release list of
+// src that match accepts. Only releases marked stable are considered,
+// unless prerelease is set.
+func findLatestRelease(src releaseSource, prerelease bool, match func(versionParts) bool) (string, error) {
+	releases, err := fetchReleases(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch available versions: %w", err)
+	}
+
+	newest := ""
+	for _, release := range releases {
+		if !release.Stable && !prerelease {
+			continue
+		}
+		parts, ok := parseVersion(release.Version)
+		if !ok || !match(parts) {
+			continue
+		}
+		if newest == "" || compareVersions(newest, release.Version) {
+			newest = release.Version
+		}
+	}
+
+	if newest == "" {
+		return "", fmt.Errorf("no matching versions found")
+	}
+	return newest, nil
+}
+
+// resolveReleaseAlias resolves latest, stable, oldstable or a bare
+// major version from the release list of src
+func resolveReleaseAlias(v string, prerelease bool, src releaseSource) (string, error) {
+	anyVersion := func(versionParts) bool { return true }
+
+	switch v {
+	case aliasLatest:
+		return findLatestRelease(src, prerelease, anyVersion)
+	case aliasStable:
+		return findLatestRelease(src, false, anyVersion)
+	case aliasOldstable:
+		stable, err := findLatestRelease(src, false, anyVersion)
+		if err != nil {
+			return "", err
+		}
+		current, _ := parseVersion(stable)
+		return findLatestRelease(src, false, func(parts versionParts) bool {
+			return parts.major < current.major || parts.major == current.major && parts.minor < current.minor
+		})
+	}
+
+	major, _ := parseVersion(strings.TrimPrefix(v, "go"))
+	return findLatestRelease(src, prerelease, func(parts versionParts) bool {
+		return parts.major == major.major
+	})
+}
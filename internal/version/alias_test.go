@@ -0,0 +1,162 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveVersionAliases(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			jsonContent := `[
+				{"version": "go1.25rc1", "stable": false, "files": []},
+				{"version": "go1.24.2", "stable": true, "files": []},
+				{"version": "go1.24.1", "stable": true, "files": []},
+				{"version": "go1.23.8", "stable": true, "files": []},
+				{"version": "go1.23.7", "stable": true, "files": []},
+				{"version": "go1.22.12", "stable": true, "files": []}
+			]`
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(jsonContent)),
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		prerelease bool
+		expected   string
+		wantErr    bool
+	}{
+		{"latest", "latest", false, "go1.24.2", false},
+		{"latest with pre-releases", "latest", true, "go1.25rc1", false},
+		{"stable", "stable", false, "go1.24.2", false},
+		{"stable ignores pre-releases", "stable", true, "go1.24.2", false},
+		{"oldstable", "oldstable", false, "go1.23.8", false},
+		{"major version", "1", false, "go1.24.2", false},
+		{"major version with go prefix", "go1", false, "go1.24.2", false},
+		{"major version with pre-releases", "1", true, "go1.25rc1", false},
+		{"unknown major version", "2", false, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := resolveVersion(tc.input, tc.prerelease, releaseSource{client: mockClient})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("resolveVersion(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+				return
+			}
+
+			if result != tc.expected {
+				t.Errorf("resolveVersion(%q) = %q, want %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVersionManager_ResolveAlias(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"versions/go1.22.4/bin/go":  "",
+		"versions/go1.23.8/bin/go":  "",
+		"versions/go1.25rc1/bin/go": "",
+		"project/go.mod":            "module example.com/project\n\ngo 1.22\n",
+		"project/.go-version":       "1.23.8\n",
+	})
+
+	testCases := []struct {
+		name       string
+		input      string
+		installDir string
+		expected   string
+		wantOutput string
+		wantErrMsg string
+	}{
+		{
+			name:       "go.mod ignores pin files",
+			input:      "go.mod",
+			expected:   "go1.22.4",
+			wantOutput: "Detected Go 1.22 from go directive",
+		},
+		{
+			name:       "installed-latest skips pre-releases",
+			input:      "installed-latest",
+			expected:   "go1.23.8",
+			wantOutput: "Resolved installed-latest to go1.23.8",
+		},
+		{
+			name:       "installed-latest without versions",
+			input:      "installed-latest",
+			installDir: filepath.Join(root, "empty"),
+			wantErrMsg: "no Go versions installed yet",
+		},
+		{
+			name:     "no alias",
+			input:    "1.24",
+			expected: "1.24",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			installDir := tc.installDir
+			if installDir == "" {
+				installDir = filepath.Join(root, "versions")
+			}
+			manager := &VersionManager{
+				fs:         tempHomeFileSystem{home: root, wd: filepath.Join(root, "project")},
+				installDir: installDir,
+			}
+
+			var buf bytes.Buffer
+			result, err := manager.resolveSpec(context.Background(), tc.input, resolveOptions{}, releaseSource{}, &buf)
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VersionManager.resolveSpec() error = %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("VersionManager.resolveSpec(%q) = %q, want %q", tc.input, result, tc.expected)
+			}
+			if !strings.Contains(buf.String(), tc.wantOutput) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tc.wantOutput, buf.String())
+			}
+		})
+	}
+}
+
+func TestVersionManager_InstallLatest(t *testing.T) {
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		httpClient: newMockRelease(t, "go1.23.8", "go1.24.2"),
+		installDir: filepath.Join(t.TempDir(), "versions"),
+	}
+
+	var buf bytes.Buffer
+	if err := manager.Install(context.Background(), "latest", InstallOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Install() error = %v, output = %s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Resolved latest to go1.24.2") {
+		t.Errorf("Expected output to mention the resolved version, got '%s'", buf.String())
+	}
+	if !manager.isComplete(filepath.Join(manager.installDir, "go1.24.2")) {
+		t.Error("Expected go1.24.2 to be installed")
+	}
+}
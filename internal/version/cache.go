@@ -0,0 +1,242 @@
+package version
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// downloadPrefix marks archives still being downloaded into the cache
+const downloadPrefix = ".download-"
+
+// archiveVersionRegex finds the Go version in the name of an archive,
+// such as go1.24.2.linux-amd64.tar.gz or, for the toolchain module,
+// v0.0.1-go1.24.2.linux-amd64.zip
+var archiveVersionRegex = regexp.MustCompile(`^(?:v0\.0\.1-)?(go\d+(?:\.\d+)*(?:(?:beta|rc)\d+)?)\.`)
+
+// cacheEntry is an archive kept in the download cache
+type cacheEntry struct {
+	path     string
+	name     string
+	size     int64
+	lastUsed time.Time
+}
+
+// cachedArchivePath returns where archive a is kept in cacheDir: a
+// folder named after its checksum, holding the archive under its own
+// name. It returns an empty string if a can not be cached.
+func cachedArchivePath(cacheDir string, a archive) string {
+	if cacheDir == "" {
+		return ""
+	}
+
+	var key string
+	switch {
+	case a.sha256 != "":
+		key = strings.ToLower(a.sha256)
+	case a.h1 != "":
+		sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(a.h1, "h1:"))
+		if err != nil {
+			return ""
+		}
+		key = hex.EncodeToString(sum)
+	}
+
+	name := path.Base(a.url)
+	if key == "" || name == "" || name == "." || name == "/" {
+		return ""
+	}
+
+	return filepath.Join(cacheDir, key, name)
+}
+
+// CacheList lists the archives in the download cache, most recently
+// used first
+func (m *VersionManager) CacheList(ctx context.Context, w io.Writer) error {
+	entries, err := m.cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "No archives cached in %s\n", m.cacheDir)
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.After(entries[j].lastUsed)
+	})
+
+	fmt.Fprintf(w, "Cached archives in %s:\n", m.cacheDir)
+	for _, entry := range entries {
+		fmt.Fprintf(w, "  %-36s %9s  last used %s\n", entry.name, formatBytes(entry.size), entry.lastUsed.Format(time.DateTime))
+	}
+	return nil
+}
+
+// CacheSize writes the total size of the download cache
+func (m *VersionManager) CacheSize(ctx context.Context, w io.Writer) error {
+	entries, err := m.cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+
+	fmt.Fprintf(w, "%s in %d cached archives\n", formatBytes(total), len(entries))
+	return nil
+}
+
+// CacheClean removes everything from the download cache, including
+// the cached release lists
+func (m *VersionManager) CacheClean(ctx context.Context, w io.Writer) error {
+	if err := m.pruneCache(0, w); err != nil {
+		return err
+	}
+	if err := m.fs.RemoveAll(filepath.Join(m.cacheDir, feedCacheDir)); err != nil {
+		return fmt.Errorf("failed to remove cached release lists: %w", err)
+	}
+	return nil
+}
+
+// CachePrune removes the archives from the download cache that have
+// not been used for longer than olderThan
+func (m *VersionManager) CachePrune(ctx context.Context, olderThan time.Duration, w io.Writer) error {
+	if olderThan <= 0 {
+		return fmt.Errorf("expected a positive age, got %s", olderThan)
+	}
+	return m.pruneCache(olderThan, w)
+}
+
+// pruneCache removes the archives not used for longer than olderThan,
+// or all archives if it is zero. Leftovers of interrupted downloads
+// are always removed, unless their version is being installed.
+func (m *VersionManager) pruneCache(olderThan time.Duration, w io.Writer) error {
+	entries, err := m.cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	removed, freed := 0, int64(0)
+	for _, entry := range entries {
+		if olderThan > 0 && time.Since(entry.lastUsed) <= olderThan {
+			continue
+		}
+
+		if err := m.fs.Remove(entry.path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry.path, err)
+		}
+		removed++
+		freed += entry.size
+	}
+
+	// Remove emptied folders and interrupted downloads
+	dirs, err := m.fs.ReadDir(m.cacheDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == feedCacheDir {
+			continue
+		}
+
+		dirPath := filepath.Join(m.cacheDir, dir.Name())
+		files, err := m.fs.ReadDir(dirPath)
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		empty := true
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name(), downloadPrefix) || !m.removePartialDownload(filepath.Join(dirPath, file.Name())) {
+				empty = false
+			}
+		}
+		if empty {
+			m.fs.Remove(dirPath)
+		}
+	}
+
+	fmt.Fprintf(w, "Removed %d cached archives, freeing %s\n", removed, formatBytes(freed))
+	return nil
+}
+
+// removePartialDownload removes what an interrupted download left at
+// path, and reports whether it did. While another gum installs the
+// version, the download may still be running and is left alone.
+func (m *VersionManager) removePartialDownload(path string) bool {
+	name := strings.TrimPrefix(filepath.Base(path), downloadPrefix)
+	if match := archiveVersionRegex.FindStringSubmatch(name); match != nil {
+		unlock, err := m.fs.TryLock(m.lockPath(match[1]))
+		var locked *lockedError
+		if errors.As(err, &locked) {
+			return false
+		}
+		if err == nil {
+			defer unlock()
+		}
+	}
+
+	return m.fs.Remove(path) == nil
+}
+
+// cacheEntries returns the archives in the download cache
+func (m *VersionManager) cacheEntries() ([]cacheEntry, error) {
+	if m.cacheDir == "" {
+		return nil, fmt.Errorf("no cache directory configured")
+	}
+
+	dirs, err := m.fs.ReadDir(m.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var entries []cacheEntry
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == feedCacheDir {
+			continue
+		}
+
+		dirPath := filepath.Join(m.cacheDir, dir.Name())
+		files, err := m.fs.ReadDir(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || strings.HasPrefix(file.Name(), downloadPrefix) {
+				continue
+			}
+
+			info, err := file.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cache: %w", err)
+			}
+
+			entries = append(entries, cacheEntry{
+				path:     filepath.Join(dirPath, file.Name()),
+				name:     file.Name(),
+				size:     info.Size(),
+				lastUsed: info.ModTime(),
+			})
+		}
+	}
+
+	return entries, nil
+}
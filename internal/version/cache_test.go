@@ -0,0 +1,248 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCachedArchivePath(t *testing.T) {
+	tests := []struct {
+		name string
+		a    archive
+		want string
+	}{
+		{
+			name: "sha256",
+			a:    archive{url: "https://go.dev/dl/go1.24.2.linux-amd64.tar.gz", sha256: "ABCDEF"},
+			want: filepath.Join("cache", "abcdef", "go1.24.2.linux-amd64.tar.gz"),
+		},
+		{
+			name: "h1",
+			a:    archive{url: "https://proxy.example.com/golang.org/toolchain/@v/v0.0.1-go1.24.2.linux-amd64.zip", h1: "h1:3q2+7w=="},
+			want: filepath.Join("cache", "deadbeef", "v0.0.1-go1.24.2.linux-amd64.zip"),
+		},
+		{
+			name: "no checksum",
+			a:    archive{url: "https://go.dev/dl/go1.24.2.linux-amd64.tar.gz"},
+			want: "",
+		},
+		{
+			name: "invalid h1",
+			a:    archive{url: "https://go.dev/dl/go1.24.2.linux-amd64.tar.gz", h1: "h1:!"},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cachedArchivePath("cache", tt.a); got != tt.want {
+				t.Errorf("cachedArchivePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := cachedArchivePath("", tests[0].a); got != "" {
+		t.Errorf("cachedArchivePath() without a cache directory = %q, want none", got)
+	}
+}
+
+func TestVersionManager_InstallCached(t *testing.T) {
+	release := newMockRelease(t, "go1.24.2")
+
+	downloads := 0
+	client := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if !strings.Contains(req.URL.String(), "mode=json") {
+				downloads++
+			}
+			return release.Do(req)
+		},
+	}
+
+	tmpDir := t.TempDir()
+	installDir := filepath.Join(tmpDir, "versions")
+	cacheDir := filepath.Join(tmpDir, "cache")
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		httpClient: client,
+		installDir: installDir,
+		cacheDir:   cacheDir,
+	}
+
+	install := func() string {
+		t.Helper()
+		os.RemoveAll(installDir)
+
+		var buf bytes.Buffer
+		if err := manager.Install(context.Background(), "go1.24.2", InstallOptions{}, &buf); err != nil {
+			t.Fatalf("VersionManager.Install() error = %v, output = %s", err, buf.String())
+		}
+		if !manager.isComplete(filepath.Join(installDir, "go1.24.2")) {
+			t.Fatal("Expected go1.24.2 to be installed")
+		}
+		return buf.String()
+	}
+
+	install()
+	if downloads != 1 {
+		t.Fatalf("Expected the archive to be downloaded once, got %d downloads", downloads)
+	}
+
+	entries, err := manager.cacheEntries()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one cached archive, got %v, error %v", entries, err)
+	}
+
+	output := install()
+	if downloads != 1 {
+		t.Errorf("Expected the cached archive to be used, got %d downloads", downloads)
+	}
+	if !strings.Contains(output, "Using cached") {
+		t.Errorf("Expected output to mention the cached archive, got '%s'", output)
+	}
+
+	// A corrupted copy is replaced by a fresh download
+	if err := os.WriteFile(entries[0].path, []byte("corrupt"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt cached archive: %v", err)
+	}
+	output = install()
+	if downloads != 2 {
+		t.Errorf("Expected the archive to be downloaded again, got %d downloads", downloads)
+	}
+	if !strings.Contains(output, "Discarding cached") {
+		t.Errorf("Expected output to mention the corrupt archive, got '%s'", output)
+	}
+	if sum, _ := fileSHA256(entries[0].path); sum != filepath.Base(filepath.Dir(entries[0].path)) {
+		t.Errorf("Expected the cached archive to be replaced, got checksum %s", sum)
+	}
+}
+
+func TestVersionManager_Cache(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	writeTestFiles(t, cacheDir, map[string]string{
+		"aaaa/go1.23.8.linux-amd64.tar.gz": "old archive",
+		"bbbb/go1.24.2.linux-amd64.tar.gz": "new archive",
+		"cccc/" + downloadPrefix + "123":   "partial",
+		feedCacheDir + "/0123.json":        "{}",
+	})
+	if err := os.Chtimes(filepath.Join(cacheDir, "aaaa", "go1.23.8.linux-amd64.tar.gz"), old, old); err != nil {
+		t.Fatalf("Failed to age archive: %v", err)
+	}
+
+	manager := &VersionManager{fs: OSFileSystem{}, cacheDir: cacheDir}
+
+	var buf bytes.Buffer
+	if err := manager.CacheList(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.CacheList() error = %v", err)
+	}
+	output := buf.String()
+	newIndex := strings.Index(output, "go1.24.2.linux-amd64.tar.gz")
+	oldIndex := strings.Index(output, "go1.23.8.linux-amd64.tar.gz")
+	if newIndex < 0 || oldIndex < 0 || newIndex > oldIndex {
+		t.Errorf("Expected both archives, most recently used first, got '%s'", output)
+	}
+	if strings.Contains(output, downloadPrefix) {
+		t.Errorf("Expected partial downloads not to be listed, got '%s'", output)
+	}
+
+	buf.Reset()
+	if err := manager.CacheSize(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.CacheSize() error = %v", err)
+	}
+	if want := "22 B in 2 cached archives"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected output to contain '%s', got '%s'", want, buf.String())
+	}
+
+	if err := manager.CachePrune(context.Background(), 0, &buf); err == nil {
+		t.Error("Expected an error for a zero age")
+	}
+
+	buf.Reset()
+	if err := manager.CachePrune(context.Background(), 30*24*time.Hour, &buf); err != nil {
+		t.Fatalf("VersionManager.CachePrune() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Removed 1 cached archives") {
+		t.Errorf("Expected one archive to be pruned, got '%s'", buf.String())
+	}
+	for path, wantExists := range map[string]bool{
+		"aaaa":                             false,
+		"bbbb/go1.24.2.linux-amd64.tar.gz": true,
+		"cccc":                             false,
+	} {
+		_, err := os.Stat(filepath.Join(cacheDir, path))
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("After prune, %s exists = %v, want %v", path, exists, wantExists)
+		}
+	}
+
+	buf.Reset()
+	if err := manager.CacheClean(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.CacheClean() error = %v", err)
+	}
+	entries, err := manager.cacheEntries()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected an empty cache, got %v, error %v", entries, err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, feedCacheDir)); !os.IsNotExist(err) {
+		t.Errorf("Expected the cached release lists to be removed, got %v", err)
+	}
+
+	buf.Reset()
+	if err := manager.CacheList(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.CacheList() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "No archives cached") {
+		t.Errorf("Expected an empty listing, got '%s'", buf.String())
+	}
+}
+
+func TestVersionManager_CacheCleanKeepsRunningDownloads(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := filepath.Join(root, "cache")
+	writeTestFiles(t, cacheDir, map[string]string{
+		"aaaa/" + downloadPrefix + "go1.24.2.linux-amd64.tar.gz":       "partial",
+		"bbbb/" + downloadPrefix + "v0.0.1-go1.26rc1.linux-amd64.zip":  "partial",
+		"cccc/" + downloadPrefix + "go1.23.8.linux-amd64.tar.gz":       "partial",
+		"dddd/" + downloadPrefix + "v0.0.1-go1.22.12.darwin-arm64.zip": "partial",
+	})
+
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		installDir: filepath.Join(root, "versions"),
+		cacheDir:   cacheDir,
+	}
+
+	// go1.24.2 and go1.26rc1 are being installed by another gum
+	for _, v := range []string{"go1.24.2", "go1.26rc1"} {
+		unlock, err := manager.lock(context.Background(), v, io.Discard)
+		if err != nil {
+			t.Fatalf("VersionManager.lock() error = %v", err)
+		}
+		defer unlock()
+	}
+
+	if err := manager.CacheClean(context.Background(), io.Discard); err != nil {
+		t.Fatalf("VersionManager.CacheClean() error = %v", err)
+	}
+
+	for path, wantExists := range map[string]bool{
+		"aaaa/" + downloadPrefix + "go1.24.2.linux-amd64.tar.gz":      true,
+		"bbbb/" + downloadPrefix + "v0.0.1-go1.26rc1.linux-amd64.zip": true,
+		"cccc": false,
+		"dddd": false,
+	} {
+		_, err := os.Stat(filepath.Join(cacheDir, path))
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("After clean, %s exists = %v, want %v", path, exists, wantExists)
+		}
+	}
+}
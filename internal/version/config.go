@@ -0,0 +1,514 @@
+package version
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// configEnvVar points gum at a config file other than the default
+	configEnvVar = "GUM_CONFIG"
+	configFile   = "config.toml"
+	// offlineEnvVar makes gum use cached release lists and archives only
+	offlineEnvVar = "GUM_OFFLINE"
+)
+
+// Config holds the settings of gum. They are read from the config
+// file, and GUM_* environment variables take precedence over it.
+type Config struct {
+	// InstallDir is where Go versions are installed
+	InstallDir string
+	// BinDir is where the binaries of the active version are linked
+	BinDir string
+	// DownloadURLs are servers laid out like go.dev/dl that releases
+	// are downloaded from, tried in order
+	DownloadURLs []string
+	// GoProxy are Go module proxies that releases are downloaded from,
+	// tried before DownloadURLs
+	GoProxy []string
+	// AuthToken is sent as a bearer token to the servers above, but
+	// never to the default go.dev
+	AuthToken string
+	// CacheDir is where downloads are kept for reuse
+	CacheDir string
+	// Timeout limits each HTTP request, zero means no limit
+	Timeout time.Duration
+	// ConnectTimeout limits connecting to a server, including the TLS
+	// handshake
+	ConnectTimeout time.Duration
+	// IdleTimeout fails requests that receive nothing for this long
+	IdleTimeout time.Duration
+	// Proxy is the proxy all requests go through, instead of the one
+	// in HTTPS_PROXY or HTTP_PROXY
+	Proxy string
+	// CABundle is a PEM file of certificates trusted on top of the
+	// system ones
+	CABundle string
+	// InsecureSkipVerify disables verifying TLS certificates
+	InsecureSkipVerify bool
+	// FeedTTL is how long cached release lists are used before they
+	// are revalidated with the mirror
+	FeedTTL time.Duration
+	// LockTimeout is how long to wait for another gum working on the
+	// same version
+	LockTimeout time.Duration
+	// Offline resolves and installs versions from the cache only
+	Offline bool
+	// AutoInstall makes commands install missing versions by default
+	AutoInstall bool
+}
+
+// configSetting describes one setting of Config
+type configSetting struct {
+	key string
+	env string
+	get func(c *Config) string
+	set func(c *Config, value string) error
+	// path settings have ~ and ${HOME} expanded
+	isPath bool
+	// secret settings are not shown by gum config list
+	secret bool
+}
+
+// configSettings lists the settings in the order they are shown
+var configSettings = []configSetting{
+	{
+		key:    "install_dir",
+		env:    "GUM_INSTALL_DIR",
+		get:    func(c *Config) string { return c.InstallDir },
+		set:    func(c *Config, value string) error { c.InstallDir = value; return nil },
+		isPath: true,
+	},
+	{
+		key:    "bin_dir",
+		env:    "GUM_BIN_DIR",
+		get:    func(c *Config) string { return c.BinDir },
+		set:    func(c *Config, value string) error { c.BinDir = value; return nil },
+		isPath: true,
+	},
+	{
+		key: "download_url",
+		env: "GUM_DOWNLOAD_URL",
+		get: func(c *Config) string { return strings.Join(c.DownloadURLs, ",") },
+		set: func(c *Config, value string) error {
+			c.DownloadURLs = nil
+			for _, u := range strings.Split(value, ",") {
+				if u = strings.TrimSpace(u); u != "" {
+					c.DownloadURLs = append(c.DownloadURLs, strings.TrimSuffix(u, "/"))
+				}
+			}
+			return nil
+		},
+	},
+	{
+		key: "goproxy",
+		env: "GUM_GOPROXY",
+		get: func(c *Config) string { return strings.Join(c.GoProxy, ",") },
+		set: func(c *Config, value string) error { c.GoProxy = parseGoProxy(value); return nil },
+	},
+	{
+		key:    "auth_token",
+		env:    "GUM_AUTH_TOKEN",
+		get:    func(c *Config) string { return c.AuthToken },
+		set:    func(c *Config, value string) error { c.AuthToken = value; return nil },
+		secret: true,
+	},
+	{
+		key:    "cache_dir",
+		env:    "GUM_CACHE_DIR",
+		get:    func(c *Config) string { return c.CacheDir },
+		set:    func(c *Config, value string) error { c.CacheDir = value; return nil },
+		isPath: true,
+	},
+	{
+		key: "timeout",
+		env: "GUM_TIMEOUT",
+		get: func(c *Config) string { return c.Timeout.String() },
+		set: func(c *Config, value string) error {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return fmt.Errorf("expected a duration such as 30s or 2m, got %q", value)
+			}
+			c.Timeout = timeout
+			return nil
+		},
+	},
+	{
+		key: "connect_timeout",
+		env: "GUM_CONNECT_TIMEOUT",
+		get: func(c *Config) string { return c.ConnectTimeout.String() },
+		set: func(c *Config, value string) error {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return fmt.Errorf("expected a duration such as 30s or 2m, got %q", value)
+			}
+			c.ConnectTimeout = timeout
+			return nil
+		},
+	},
+	{
+		key: "idle_timeout",
+		env: "GUM_IDLE_TIMEOUT",
+		get: func(c *Config) string { return c.IdleTimeout.String() },
+		set: func(c *Config, value string) error {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return fmt.Errorf("expected a duration such as 30s or 2m, got %q", value)
+			}
+			c.IdleTimeout = timeout
+			return nil
+		},
+	},
+	{
+		key: "proxy",
+		env: "GUM_PROXY",
+		get: func(c *Config) string { return c.Proxy },
+		set: func(c *Config, value string) error {
+			if _, err := parseProxyURL(value); err != nil {
+				return err
+			}
+			c.Proxy = value
+			return nil
+		},
+	},
+	{
+		key:    "ca_bundle",
+		env:    "GUM_CA_BUNDLE",
+		get:    func(c *Config) string { return c.CABundle },
+		set:    func(c *Config, value string) error { c.CABundle = value; return nil },
+		isPath: true,
+	},
+	{
+		key: "insecure_skip_verify",
+		env: "GUM_INSECURE_SKIP_VERIFY",
+		get: func(c *Config) string { return strconv.FormatBool(c.InsecureSkipVerify) },
+		set: func(c *Config, value string) error {
+			insecure, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("expected true or false, got %q", value)
+			}
+			c.InsecureSkipVerify = insecure
+			return nil
+		},
+	},
+	{
+		key: "feed_ttl",
+		env: "GUM_FEED_TTL",
+		get: func(c *Config) string { return c.FeedTTL.String() },
+		set: func(c *Config, value string) error {
+			ttl, err := time.ParseDuration(value)
+			if err != nil || ttl < 0 {
+				return fmt.Errorf("expected a duration such as 30m or 24h, got %q", value)
+			}
+			c.FeedTTL = ttl
+			return nil
+		},
+	},
+	{
+		key: "lock_timeout",
+		env: "GUM_LOCK_TIMEOUT",
+		get: func(c *Config) string { return c.LockTimeout.String() },
+		set: func(c *Config, value string) error {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return fmt.Errorf("expected a duration such as 30s or 10m, got %q", value)
+			}
+			c.LockTimeout = timeout
+			return nil
+		},
+	},
+	{
+		key: "offline",
+		env: offlineEnvVar,
+		get: func(c *Config) string { return strconv.FormatBool(c.Offline) },
+		set: func(c *Config, value string) error {
+			offline, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("expected true or false, got %q", value)
+			}
+			c.Offline = offline
+			return nil
+		},
+	},
+	{
+		key: "auto_install",
+		env: autoInstallEnvVar,
+		get: func(c *Config) string { return strconv.FormatBool(c.AutoInstall) },
+		set: func(c *Config, value string) error {
+			autoInstall, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("expected true or false, got %q", value)
+			}
+			c.AutoInstall = autoInstall
+			return nil
+		},
+	},
+}
+
+// DefaultConfig returns the settings used when nothing is configured
+func DefaultConfig() Config {
+	return defaultConfig(OSFileSystem{})
+}
+
+func defaultConfig(fs FileSystem) Config {
+	return Config{
+		InstallDir:     expandPath(defaultInstallDir, fs),
+		BinDir:         expandPath(defaultBinDir, fs),
+		DownloadURLs:   []string{defaultDownloadURL},
+		CacheDir:       expandPath(defaultCacheDir, fs),
+		ConnectTimeout: defaultConnectTimeout,
+		IdleTimeout:    defaultIdleTimeout,
+		FeedTTL:        defaultFeedTTL,
+		LockTimeout:    defaultLockTimeout,
+	}
+}
+
+// LoadConfig reads the config file, if there is one, and applies the
+// GUM_* environment variables on top of it. If some settings are
+// broken or the file can not be read, it returns an error along with
+// the config made of the rest.
+func LoadConfig() (Config, error) {
+	return loadConfig(OSFileSystem{})
+}
+
+func loadConfig(fs FileSystem) (Config, error) {
+	config := defaultConfig(fs)
+
+	path := configPath(fs)
+	values, err := readConfigFile(fs, path)
+	errs := []error{err}
+
+	for _, setting := range configSettings {
+		value, ok := values[setting.key]
+		source := path
+		if env := os.Getenv(setting.env); env != "" {
+			value, ok, source = env, true, setting.env
+		}
+		// Like an empty environment variable, an empty value keeps the default
+		if !ok || value == "" {
+			continue
+		}
+
+		if setting.isPath {
+			value = expandPath(value, fs)
+		}
+		if err := setting.set(&config, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s in %s: %w", setting.key, source, err))
+		}
+	}
+
+	return config, errors.Join(errs...)
+}
+
+// configPath returns the location of the config file: $GUM_CONFIG,
+// $XDG_CONFIG_HOME/gum/config.toml, or ~/.gum/config.toml
+func configPath(fs FileSystem) string {
+	if path := os.Getenv(configEnvVar); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gum", configFile)
+	}
+	home, _ := fs.UserHomeDir()
+	return filepath.Join(home, ".gum", configFile)
+}
+
+// readConfigFile reads the settings of a config file. It understands
+// the subset of TOML gum writes: one key = value pair per line, where
+// values are quoted strings, booleans or numbers. Lines it does not
+// understand are reported in the error, along with the other settings.
+func readConfigFile(fs FileSystem, path string) (map[string]string, error) {
+	values := map[string]string{}
+
+	file, err := fs.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return values, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer file.Close()
+
+	var errs []error
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := parseConfigLine(line)
+		if !ok {
+			errs = append(errs, fmt.Errorf("invalid line %d in %s: expected key = value", lineNum, path))
+			continue
+		}
+		if findConfigSetting(key) == nil {
+			errs = append(errs, fmt.Errorf("unknown setting %q on line %d in %s", key, lineNum, path))
+			continue
+		}
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// parseConfigLine splits a key = value line, unquoting the value
+func parseConfigLine(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(value, `"`) {
+		// A comment may follow the closing quote
+		quoted, err := strconv.QuotedPrefix(value)
+		if err != nil {
+			return "", "", false
+		}
+		if rest := strings.TrimSpace(value[len(quoted):]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", "", false
+		}
+		// An empty quoted string is a value too, clearing the setting
+		value, _ = strconv.Unquote(quoted)
+		return key, value, key != ""
+	} else if before, _, found := strings.Cut(value, "#"); found {
+		value = strings.TrimSpace(before)
+	}
+
+	return key, value, key != "" && value != ""
+}
+
+// formatConfigValue formats value the way it is written to the config file
+func formatConfigValue(value string) string {
+	if _, err := strconv.ParseBool(value); err == nil {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func findConfigSetting(key string) *configSetting {
+	for i := range configSettings {
+		if configSettings[i].key == key {
+			return &configSettings[i]
+		}
+	}
+	return nil
+}
+
+// ConfigGet writes the value of the setting key. If the config is
+// broken, the value is still written before reporting the error.
+func (m *VersionManager) ConfigGet(ctx context.Context, key string, w io.Writer) error {
+	setting := findConfigSetting(key)
+	if setting == nil {
+		return unknownSettingError(key)
+	}
+
+	config, loadErr := loadConfig(m.fs)
+	if _, err := fmt.Fprintln(w, setting.get(&config)); err != nil {
+		return err
+	}
+	return loadErr
+}
+
+// ConfigSet stores value for the setting key in the config file,
+// keeping the rest of the file as it is
+func (m *VersionManager) ConfigSet(ctx context.Context, key, value string, w io.Writer) error {
+	setting := findConfigSetting(key)
+	if setting == nil {
+		return unknownSettingError(key)
+	}
+
+	// Check the value before writing it
+	var check Config
+	if err := setting.set(&check, value); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	path := configPath(m.fs)
+	var lines []string
+	if file, err := m.fs.Open(path); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	line := fmt.Sprintf("%s = %s", key, formatConfigValue(value))
+	replaced := false
+	for i, existing := range lines {
+		if existingKey, _, ok := parseConfigLine(strings.TrimSpace(existing)); ok && existingKey == key {
+			lines[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+
+	if err := m.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := m.fs.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	fmt.Fprintf(w, "Set %s to %s in %s\n", key, value, path)
+	if os.Getenv(setting.env) != "" {
+		fmt.Fprintf(w, "Note: %s is set and takes precedence\n", setting.env)
+	}
+	// Other settings may still be broken
+	if _, err := loadConfig(m.fs); err != nil {
+		fmt.Fprintf(w, "Warning: %v\n", err)
+	}
+	return nil
+}
+
+// ConfigList writes every setting and its value, in config file syntax.
+// If the config is broken, the settings are still written before
+// reporting the error.
+func (m *VersionManager) ConfigList(ctx context.Context, w io.Writer) error {
+	config, loadErr := loadConfig(m.fs)
+
+	fmt.Fprintf(w, "# %s\n", configPath(m.fs))
+	for _, setting := range configSettings {
+		value := setting.get(&config)
+		if setting.secret && value != "" {
+			value = "********"
+		}
+
+		line := fmt.Sprintf("%s = %s", setting.key, formatConfigValue(value))
+		if os.Getenv(setting.env) != "" {
+			line += " # from " + setting.env
+		}
+		fmt.Fprintln(w, line)
+	}
+	return loadErr
+}
+
+func unknownSettingError(key string) error {
+	keys := make([]string, len(configSettings))
+	for i, setting := range configSettings {
+		keys[i] = setting.key
+	}
+	return fmt.Errorf("unknown setting %q, expected one of %s", key, strings.Join(keys, ", "))
+}
@@ -0,0 +1,286 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearConfigEnv unsets every environment variable overriding the config
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv(configEnvVar, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	for _, setting := range configSettings {
+		t.Setenv(setting.env, "")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name       string
+		content    string
+		env        map[string]string
+		expected   Config
+		wantErrMsg string
+	}{
+		{
+			name: "defaults",
+			expected: Config{
+				InstallDir:     "/mock/home/.gum/versions",
+				BinDir:         "/mock/home/.gum/bin",
+				DownloadURLs:   []string{"https://go.dev/dl"},
+				CacheDir:       "/mock/home/.gum/cache",
+				ConnectTimeout: 30 * time.Second,
+				IdleTimeout:    time.Minute,
+				FeedTTL:        time.Hour,
+				LockTimeout:    10 * time.Minute,
+			},
+		},
+		{
+			name: "config file",
+			content: `# gum settings
+install_dir = "~/sdk"
+download_url = "https://mirror.example.com/go/" # trailing slash is dropped
+goproxy = "https://proxy.example.com|direct"
+timeout = "30s"
+idle_timeout = "2m"
+ca_bundle = "~/corp-ca.pem"
+feed_ttl = "24h"
+auto_install = true
+`,
+			expected: Config{
+				InstallDir:     "/mock/home/sdk",
+				BinDir:         "/mock/home/.gum/bin",
+				DownloadURLs:   []string{"https://mirror.example.com/go"},
+				GoProxy:        []string{"https://proxy.example.com"},
+				CacheDir:       "/mock/home/.gum/cache",
+				Timeout:        30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				IdleTimeout:    2 * time.Minute,
+				CABundle:       "/mock/home/corp-ca.pem",
+				FeedTTL:        24 * time.Hour,
+				LockTimeout:    10 * time.Minute,
+				AutoInstall:    true,
+			},
+		},
+		{
+			name:    "environment takes precedence",
+			content: "install_dir = \"/opt/go\"\nauto_install = true\n",
+			env: map[string]string{
+				"GUM_INSTALL_DIR":  "/srv/go",
+				"GUM_AUTO_INSTALL": "false",
+				"GUM_BIN_DIR":      "${HOME}/bin",
+				"GUM_OFFLINE":      "1",
+				"GUM_PROXY":        "proxy.example.com:3128",
+			},
+			expected: Config{
+				InstallDir:     "/srv/go",
+				BinDir:         "/mock/home/bin",
+				DownloadURLs:   []string{"https://go.dev/dl"},
+				CacheDir:       "/mock/home/.gum/cache",
+				ConnectTimeout: 30 * time.Second,
+				IdleTimeout:    time.Minute,
+				Proxy:          "proxy.example.com:3128",
+				FeedTTL:        time.Hour,
+				LockTimeout:    10 * time.Minute,
+				Offline:        true,
+			},
+		},
+		{
+			name:    "empty values keep the defaults",
+			content: "install_dir = \"\"\nproxy = \"\" # no proxy\nauth_token = \"\"\n",
+			expected: Config{
+				InstallDir:     "/mock/home/.gum/versions",
+				BinDir:         "/mock/home/.gum/bin",
+				DownloadURLs:   []string{"https://go.dev/dl"},
+				CacheDir:       "/mock/home/.gum/cache",
+				ConnectTimeout: 30 * time.Second,
+				IdleTimeout:    time.Minute,
+				FeedTTL:        time.Hour,
+				LockTimeout:    10 * time.Minute,
+			},
+		},
+		{
+			name:       "unknown setting",
+			content:    "install_directory = \"/opt/go\"\n",
+			wantErrMsg: `unknown setting "install_directory" on line 1`,
+		},
+		{
+			name:       "invalid line",
+			content:    "# settings\n[paths]\n",
+			wantErrMsg: "invalid line 2",
+		},
+		{
+			name:       "invalid value",
+			content:    "timeout = \"soon\"\n",
+			wantErrMsg: "invalid timeout in /mock/home/.gum/config.toml",
+		},
+		{
+			name:       "invalid proxy",
+			content:    "proxy = \"http://\"\n",
+			wantErrMsg: "invalid proxy",
+		},
+		{
+			name:       "invalid environment value",
+			env:        map[string]string{"GUM_AUTO_INSTALL": "sometimes"},
+			wantErrMsg: "invalid auto_install in GUM_AUTO_INSTALL",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			mockFS := &MockFileSystem{ExistingFiles: map[string]bool{}}
+			if tc.content != "" {
+				mockFS.ExistingFiles["/mock/home/.gum/config.toml"] = true
+				mockFS.FileContents = map[string]string{"/mock/home/.gum/config.toml": tc.content}
+			}
+
+			config, err := loadConfig(mockFS)
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(config, tc.expected) {
+				t.Errorf("loadConfig() = %+v, want %+v", config, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	clearConfigEnv(t)
+	mockFS := &MockFileSystem{}
+
+	if path := configPath(mockFS); path != "/mock/home/.gum/config.toml" {
+		t.Errorf("configPath() = %s, want /mock/home/.gum/config.toml", path)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "/mock/home/.config")
+	if path := configPath(mockFS); path != "/mock/home/.config/gum/config.toml" {
+		t.Errorf("configPath() = %s, want /mock/home/.config/gum/config.toml", path)
+	}
+
+	t.Setenv(configEnvVar, "/etc/gum.toml")
+	if path := configPath(mockFS); path != "/etc/gum.toml" {
+		t.Errorf("configPath() = %s, want /etc/gum.toml", path)
+	}
+}
+
+func TestVersionManager_ConfigSet(t *testing.T) {
+	clearConfigEnv(t)
+
+	path := filepath.Join(t.TempDir(), "gum", "config.toml")
+	t.Setenv(configEnvVar, path)
+
+	manager := &VersionManager{fs: OSFileSystem{}}
+
+	var buf bytes.Buffer
+	if err := manager.ConfigSet(context.Background(), "timeout", "1m", &buf); err != nil {
+		t.Fatalf("VersionManager.ConfigSet() error = %v", err)
+	}
+
+	// Comments and other settings are kept, existing settings replaced
+	content := "# my settings\ninstall_dir = \"/opt/go\"\ntimeout = \"1m\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	for _, kv := range [][2]string{{"install_dir", "/srv/go"}, {"auto_install", "true"}} {
+		if err := manager.ConfigSet(context.Background(), kv[0], kv[1], &buf); err != nil {
+			t.Fatalf("VersionManager.ConfigSet() error = %v", err)
+		}
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	want := "# my settings\ninstall_dir = \"/srv/go\"\ntimeout = \"1m\"\nauto_install = true\n"
+	if string(written) != want {
+		t.Errorf("Config file = %q, want %q", string(written), want)
+	}
+
+	if err := manager.ConfigSet(context.Background(), "timeout", "soon", &buf); err == nil {
+		t.Error("Expected error for invalid value, got nil")
+	}
+	if err := manager.ConfigSet(context.Background(), "colour", "blue", &buf); err == nil {
+		t.Error("Expected error for unknown setting, got nil")
+	}
+
+	buf.Reset()
+	t.Setenv("GUM_INSTALL_DIR", "/tmp/go")
+	if err := manager.ConfigList(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.ConfigList() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"install_dir = \"/tmp/go\" # from GUM_INSTALL_DIR\n",
+		"timeout = \"1m0s\"\n",
+		"auto_install = true\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected output to contain '%s', got '%s'", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := manager.ConfigGet(context.Background(), "auto_install", &buf); err != nil {
+		t.Fatalf("VersionManager.ConfigGet() error = %v", err)
+	}
+	if buf.String() != "true\n" {
+		t.Errorf("VersionManager.ConfigGet() = %q, want %q", buf.String(), "true\n")
+	}
+}
+
+func TestVersionManager_ConfigSetEmpty(t *testing.T) {
+	clearConfigEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv(configEnvVar, path)
+
+	manager := &VersionManager{fs: OSFileSystem{}}
+
+	for _, kv := range [][2]string{{"proxy", "proxy.example.com:3128"}, {"auth_token", "secret"}, {"proxy", ""}, {"auth_token", ""}} {
+		if err := manager.ConfigSet(context.Background(), kv[0], kv[1], io.Discard); err != nil {
+			t.Fatalf("VersionManager.ConfigSet(%q, %q) error = %v", kv[0], kv[1], err)
+		}
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Proxy != "" || config.AuthToken != "" {
+		t.Errorf("LoadConfig() Proxy = %q, AuthToken = %q, want both cleared", config.Proxy, config.AuthToken)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if want := "proxy = \"\"\nauth_token = \"\"\n"; string(written) != want {
+		t.Errorf("Config file = %q, want %q", string(written), want)
+	}
+}
@@ -0,0 +1,286 @@
+package version
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// constraintOperators lists the operators a constraint term may start
+// with, longest first so >= is not mistaken for >
+var constraintOperators = []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+// versionConstraint is a range of versions, such as ">=1.22 <1.24" or
+// "~1.23". Terms separated by spaces or commas must all match, and
+// alternatives are separated by ||.
+type versionConstraint struct {
+	text string
+	// alternatives holds the terms of each alternative
+	alternatives [][]constraintTerm
+}
+
+// constraintTerm is a single comparison, such as ">=1.22"
+type constraintTerm struct {
+	op      string
+	version versionParts
+	// precision is how many parts the version names: 1 for "1", 2 for
+	// "1.22" and 3 for "1.22.3" or a pre-release such as "1.26rc1"
+	precision int
+}
+
+// isVersionConstraint reports whether v is a constraint rather than a
+// single version
+func isVersionConstraint(v string) bool {
+	return strings.ContainsAny(v, "<>=!~^*|, ") || strings.HasSuffix(v, ".x")
+}
+
+// parseVersionConstraint parses a constraint such as ">=1.22 <1.24",
+// "~1.23", "^1.21.5", "1.22.x" or ">=1.21 || 1.19.x"
+func parseVersionConstraint(text string) (versionConstraint, error) {
+	c := versionConstraint{text: strings.TrimSpace(text)}
+
+	for _, alternative := range strings.Split(text, "||") {
+		fields := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+
+		var terms []constraintTerm
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// Allow a space between operator and version, as in ">= 1.22"
+			if slices.Contains(constraintOperators, field) && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+
+			term, err := parseConstraintTerm(field)
+			if err != nil {
+				return versionConstraint{}, fmt.Errorf("invalid version constraint %q: %w", c.text, err)
+			}
+			terms = append(terms, term)
+		}
+
+		if len(terms) == 0 {
+			return versionConstraint{}, fmt.Errorf("invalid version constraint %q: empty alternative", c.text)
+		}
+		c.alternatives = append(c.alternatives, terms)
+	}
+
+	return c, nil
+}
+
+// parseConstraintTerm parses a single term of a constraint
+func parseConstraintTerm(field string) (constraintTerm, error) {
+	term := constraintTerm{op: "="}
+	for _, op := range constraintOperators {
+		if rest, ok := strings.CutPrefix(field, op); ok {
+			term.op, field = op, rest
+			break
+		}
+	}
+	if term.op == "==" {
+		term.op = "="
+	}
+
+	v := strings.TrimPrefix(field, "go")
+	// Wildcards stand for every version of a release series
+	for _, wildcard := range []string{".x", ".X", ".*"} {
+		v = strings.TrimSuffix(v, wildcard)
+	}
+	if v == "*" || v == "x" || v == "X" {
+		return constraintTerm{op: "*"}, nil
+	}
+
+	match := versionPartsRegex.FindStringSubmatch(v)
+	if match == nil {
+		return constraintTerm{}, fmt.Errorf("%q is not a Go version", field)
+	}
+
+	term.version, _ = parseVersion(v)
+	switch {
+	case match[3] != "" || match[4] != "":
+		term.precision = 3
+	case match[2] != "":
+		term.precision = 2
+	default:
+		term.precision = 1
+	}
+
+	if term.op == "~" && term.precision == 1 {
+		// ~1 is every 1.x release, just like ^1
+		term.op = "^"
+	}
+	return term, nil
+}
+
+// String returns the constraint as it was written
+func (c versionConstraint) String() string {
+	return c.text
+}
+
+// allows reports whether version v satisfies the constraint.
+// Pre-releases are only allowed if prerelease is set.
+func (c versionConstraint) allows(v string, prerelease bool) bool {
+	parts, ok := parseVersion(v)
+	if !ok || parts.stage != stageRelease && !prerelease {
+		return false
+	}
+
+	for _, terms := range c.alternatives {
+		if !slices.ContainsFunc(terms, func(term constraintTerm) bool { return !term.allows(parts) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether v satisfies the term. A version naming fewer
+// parts stands for its whole series, so "<=1.22" allows 1.22.5 while
+// "<1.22" does not allow 1.22rc1.
+func (t constraintTerm) allows(v versionParts) bool {
+	first := t.first()
+	switch t.op {
+	case "*":
+		return true
+	case "=":
+		return t.contains(v)
+	case "!=":
+		return !t.contains(v)
+	case ">":
+		if t.precision == 3 {
+			return compareParts(v, t.version) > 0
+		}
+		return compareParts(v, t.after()) >= 0
+	case ">=":
+		return compareParts(v, first) >= 0
+	case "<":
+		return compareParts(v, first) < 0
+	case "<=":
+		if t.precision == 3 {
+			return compareParts(v, t.version) <= 0
+		}
+		return compareParts(v, t.after()) < 0
+	case "~":
+		// ~1.23.4 allows later patches of 1.23, ~1.23 all of 1.23
+		series := constraintTerm{version: t.version, precision: 2}
+		return compareParts(v, first) >= 0 && compareParts(v, series.after()) < 0
+	case "^":
+		// ^1.22 allows everything up to the next major version
+		major := constraintTerm{version: t.version, precision: 1}
+		return compareParts(v, first) >= 0 && compareParts(v, major.after()) < 0
+	}
+	return false
+}
+
+// contains reports whether v is the version of the term, or belongs to
+// the series it names
+func (t constraintTerm) contains(v versionParts) bool {
+	if t.precision == 3 {
+		return compareParts(v, t.version) == 0
+	}
+	return compareParts(v, t.first()) >= 0 && compareParts(v, t.after()) < 0
+}
+
+// first returns the first version of the series the term names, which
+// is below any of its pre-releases
+func (t constraintTerm) first() versionParts {
+	switch t.precision {
+	case 1:
+		return versionParts{major: t.version.major, stage: stageBeta}
+	case 2:
+		return versionParts{major: t.version.major, minor: t.version.minor, stage: stageBeta}
+	}
+	return t.version
+}
+
+// after returns the first version after the series the term names
+func (t constraintTerm) after() versionParts {
+	switch t.precision {
+	case 1:
+		return versionParts{major: t.version.major + 1, stage: stageBeta}
+	case 2:
+		return versionParts{major: t.version.major, minor: t.version.minor + 1, stage: stageBeta}
+	}
+	return versionParts{major: t.version.major, minor: t.version.minor, patch: t.version.patch + 1, stage: stageBeta}
+}
+
+// nearest returns the versions closest to the bounds of the constraint,
+// to suggest when none of versions satisfies it
+func (c versionConstraint) nearest(versions []string) []string {
+	sorted := slices.Clone(versions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareVersions(sorted[i], sorted[j])
+	})
+
+	var nearest []string
+	for _, terms := range c.alternatives {
+		for _, term := range terms {
+			if term.op == "*" {
+				continue
+			}
+
+			// The versions on either side of where the term's version would be
+			i := sort.Search(len(sorted), func(i int) bool {
+				parts, _ := parseVersion(sorted[i])
+				return compareParts(parts, term.first()) >= 0
+			})
+			for _, j := range []int{i - 1, i} {
+				if j >= 0 && j < len(sorted) && !slices.Contains(nearest, sorted[j]) {
+					nearest = append(nearest, sorted[j])
+				}
+			}
+		}
+	}
+
+	sort.Slice(nearest, func(i, j int) bool {
+		return compareVersions(nearest[j], nearest[i]) // reverse for newest first
+	})
+	return nearest
+}
+
+// resolveConstraint picks the newest version satisfying the constraint.
+// With preferInstalled, the newest installed version satisfying it is
+// used if there is one, before looking at versions available for
+// download.
+func (m *VersionManager) resolveConstraint(c versionConstraint, opts resolveOptions, src releaseSource, w io.Writer) (string, error) {
+	if opts.preferInstalled {
+		installed, err := m.installedVersions()
+		if err != nil {
+			return "", err
+		}
+		for _, v := range installed {
+			if c.allows(v, opts.prerelease) {
+				fmt.Fprintf(w, "Resolved %s to installed %s\n", c, v)
+				return v, nil
+			}
+		}
+	}
+
+	releases, err := fetchReleases(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch available versions: %w", err)
+	}
+
+	var available []string
+	for _, release := range releases {
+		if release.Stable || opts.prerelease {
+			available = append(available, release.Version)
+		}
+	}
+	sort.Slice(available, func(i, j int) bool {
+		return compareVersions(available[j], available[i]) // reverse for newest first
+	})
+
+	for _, v := range available {
+		if c.allows(v, opts.prerelease) {
+			fmt.Fprintf(w, "Resolved %s to %s\n", c, v)
+			return v, nil
+		}
+	}
+
+	nearest := c.nearest(available)
+	if len(nearest) == 0 {
+		return "", fmt.Errorf("no Go version matches %s", c)
+	}
+	return "", fmt.Errorf("no Go version matches %s, nearest available: %s", c, strings.Join(nearest, ", "))
+}
@@ -0,0 +1,259 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsVersionConstraint(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{">=1.22 <1.24", true},
+		{"~1.23", true},
+		{"^1.21.5", true},
+		{"1.22.x", true},
+		{"!=1.23.1", true},
+		{"1.21 || 1.23", true},
+		{"1.24", false},
+		{"go1.24.2", false},
+		{"1.26rc1", false},
+		{"latest", false},
+		{"installed-latest", false},
+		{"go.mod", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := isVersionConstraint(tc.input); result != tc.expected {
+				t.Errorf("isVersionConstraint(%q) = %v, want %v", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseVersionConstraint(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"range", ">=1.22 <1.24", false},
+		{"comma separated", ">=1.22, <1.24", false},
+		{"space after operator", ">= 1.22 < 1.24", false},
+		{"alternatives", "1.21.x || >=1.23", false},
+		{"go prefix", ">=go1.22", false},
+		{"wildcard", "*", false},
+		{"not a version", ">=one", true},
+		{"empty alternative", "1.22 ||", true},
+		{"dangling operator", ">=", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseVersionConstraint(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("parseVersionConstraint(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVersionConstraint_Allows(t *testing.T) {
+	testCases := []struct {
+		constraint string
+		version    string
+		prerelease bool
+		expected   bool
+	}{
+		{">=1.22 <1.24", "go1.22.0", false, true},
+		{">=1.22 <1.24", "go1.23.8", false, true},
+		{">=1.22 <1.24", "go1.24.0", false, false},
+		{">=1.22 <1.24", "go1.21.13", false, false},
+		{">=1.22 <1.24", "go1.24rc1", true, false},
+		{">=1.22", "go1.22rc1", true, true},
+		{">=1.22", "go1.22rc1", false, false},
+		{">1.22", "go1.22.5", false, false},
+		{">1.22", "go1.23.0", false, true},
+		{">1.22.5", "go1.22.6", false, true},
+		{"<=1.22", "go1.22.5", false, true},
+		{"<=1.22.5", "go1.22.6", false, false},
+		{"~1.23", "go1.23.0", false, true},
+		{"~1.23", "go1.24.0", false, false},
+		{"~1.23.4", "go1.23.3", false, false},
+		{"~1.23.4", "go1.23.8", false, true},
+		{"~1", "go1.24.2", false, true},
+		{"^1.21.5", "go1.24.2", false, true},
+		{"^1.21.5", "go1.21.4", false, false},
+		{"^1.21.5", "go2.0.0", false, false},
+		{"1.22.x", "go1.22.12", false, true},
+		{"1.22.x", "go1.23.0", false, false},
+		{"=1.22.3", "go1.22.3", false, true},
+		{"!=1.23.1", "go1.23.1", false, false},
+		{"!=1.23", "go1.23.4", false, false},
+		{"1.21.x || >=1.23", "go1.21.13", false, true},
+		{"1.21.x || >=1.23", "go1.22.12", false, false},
+		{"1.21.x || >=1.23", "go1.24.2", false, true},
+		{"*", "go1.24.2", false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.constraint+" "+tc.version, func(t *testing.T) {
+			c, err := parseVersionConstraint(tc.constraint)
+			if err != nil {
+				t.Fatalf("parseVersionConstraint(%q) error = %v", tc.constraint, err)
+			}
+			if result := c.allows(tc.version, tc.prerelease); result != tc.expected {
+				t.Errorf("%q allows %s = %v, want %v", tc.constraint, tc.version, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVersionConstraint_Nearest(t *testing.T) {
+	versions := []string{"go1.21.13", "go1.22.12", "go1.23.8", "go1.24.2"}
+
+	testCases := []struct {
+		constraint string
+		expected   []string
+	}{
+		{">=1.25", []string{"go1.24.2"}},
+		{"<1.21", []string{"go1.21.13"}},
+		{"~1.22.20", []string{"go1.23.8", "go1.22.12"}},
+		{">1.22.12 <1.23", []string{"go1.23.8", "go1.22.12", "go1.21.13"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.constraint, func(t *testing.T) {
+			c, err := parseVersionConstraint(tc.constraint)
+			if err != nil {
+				t.Fatalf("parseVersionConstraint(%q) error = %v", tc.constraint, err)
+			}
+			result := c.nearest(versions)
+			if strings.Join(result, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("nearest(%q) = %v, want %v", tc.constraint, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVersionManager_ResolveConstraint(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			jsonContent := `[
+				{"version": "go1.25rc1", "stable": false, "files": []},
+				{"version": "go1.24.2", "stable": true, "files": []},
+				{"version": "go1.23.8", "stable": true, "files": []},
+				{"version": "go1.23.7", "stable": true, "files": []},
+				{"version": "go1.22.12", "stable": true, "files": []}
+			]`
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(jsonContent)),
+			}, nil
+		},
+	}
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"versions/go1.22.4/bin/go": "",
+		"versions/go1.23.7/bin/go": "",
+	})
+
+	testCases := []struct {
+		name       string
+		input      string
+		opts       resolveOptions
+		expected   string
+		wantOutput string
+		wantErrMsg string
+	}{
+		{
+			name:       "install picks newest available",
+			input:      ">=1.22 <1.24",
+			expected:   "go1.23.8",
+			wantOutput: "Resolved >=1.22 <1.24 to go1.23.8",
+		},
+		{
+			name:       "use prefers installed",
+			input:      ">=1.22 <1.24",
+			opts:       resolveOptions{preferInstalled: true},
+			expected:   "go1.23.7",
+			wantOutput: "Resolved >=1.22 <1.24 to installed go1.23.7",
+		},
+		{
+			name:     "use falls back to available",
+			input:    "~1.24",
+			opts:     resolveOptions{preferInstalled: true},
+			expected: "go1.24.2",
+		},
+		{
+			name:     "pre-release",
+			input:    ">1.24",
+			opts:     resolveOptions{prerelease: true},
+			expected: "go1.25rc1",
+		},
+		{
+			name:       "no match lists nearest",
+			input:      ">=1.25",
+			wantErrMsg: "no Go version matches >=1.25, nearest available: go1.24.2",
+		},
+		{
+			name:       "invalid constraint",
+			input:      ">=latest",
+			wantErrMsg: "invalid version constraint",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &VersionManager{
+				fs:         tempHomeFileSystem{home: root},
+				installDir: filepath.Join(root, "versions"),
+			}
+
+			var buf bytes.Buffer
+			result, err := manager.resolveSpec(context.Background(), tc.input, tc.opts, releaseSource{client: mockClient}, &buf)
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VersionManager.resolveSpec() error = %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("VersionManager.resolveSpec(%q) = %q, want %q", tc.input, result, tc.expected)
+			}
+			if !strings.Contains(buf.String(), tc.wantOutput) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tc.wantOutput, buf.String())
+			}
+		})
+	}
+}
+
+func TestVersionManager_InstallConstraint(t *testing.T) {
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		httpClient: newMockRelease(t, "go1.22.12", "go1.23.8", "go1.24.2"),
+		installDir: filepath.Join(t.TempDir(), "versions"),
+	}
+
+	var buf bytes.Buffer
+	if err := manager.Install(context.Background(), ">=1.22 <1.24", InstallOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Install() error = %v, output = %s", err, buf.String())
+	}
+	if !manager.isComplete(filepath.Join(manager.installDir, "go1.23.8")) {
+		t.Errorf("Expected go1.23.8 to be installed, output = %s", buf.String())
+	}
+}
@@ -0,0 +1,252 @@
+package version
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	// versionEnvVar overrides the version detected for the current directory
+	versionEnvVar = "GUM_GO_VERSION"
+	// pinFile is the file written by Pin
+	pinFile = ".gum-version"
+)
+
+// pinFiles lists the files pinning a project's Go version, in order of precedence
+var pinFiles = []string{pinFile, ".go-version"}
+
+// requestedVersion is the Go version requested for the current directory
+type requestedVersion struct {
+	// Version is either a concrete release (e.g. "1.22.3") or
+	// a release series such as a go.mod language version (e.g. "1.22")
+	Version string
+	// Source describes where the version was found
+	Source string
+}
+
+// detectVersion determines the Go version requested for the current
+// directory. GUM_GO_VERSION takes precedence. Otherwise the current
+// directory and then each of its parents is checked for a .gum-version
+// file, a .go-version file and a go.mod or go.work file, in that order,
+// so the nearest of them wins.
+func detectVersion(fs FileSystem) (requestedVersion, error) {
+	if v := strings.TrimSpace(os.Getenv(versionEnvVar)); v != "" {
+		return requestedVersion{Version: v, Source: versionEnvVar}, nil
+	}
+
+	cwd, err := fs.Getwd()
+	if err != nil {
+		return requestedVersion{}, fmt.Errorf("could not get current directory: %w", err)
+	}
+
+	// The nearest declaration wins, so each directory is checked for
+	// all of them before moving on to its parent
+	for dir := cwd; ; {
+		for _, name := range pinFiles {
+			path := filepath.Join(dir, name)
+			if _, err := fs.Stat(path); err == nil {
+				return readPinFile(fs, path)
+			}
+		}
+		if isModuleRoot(fs, dir) {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return detectVersionInGoMod(fs)
+}
+
+// isModuleRoot reports whether dir holds a go.mod, or a go.work file
+// that is not disabled by GOWORK
+func isModuleRoot(fs FileSystem, dir string) bool {
+	if _, err := fs.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		return true
+	}
+	if gowork := os.Getenv("GOWORK"); gowork == "" || gowork == "auto" {
+		_, err := fs.Stat(filepath.Join(dir, "go.work"))
+		return err == nil
+	}
+	return false
+}
+
+// readPinFile reads the version from a .gum-version or .go-version file,
+// which holds a single version and optionally # comments
+func readPinFile(fs FileSystem, path string) (requestedVersion, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return requestedVersion{}, fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return requestedVersion{Version: strings.TrimPrefix(line, "go"), Source: path}, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return requestedVersion{}, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return requestedVersion{}, fmt.Errorf("no Go version found in %s", path)
+}
+
+// detectVersionInGoMod finds the go.mod of the module containing the
+// current directory, searching parent directories up to the module root,
+// and extracts the requested Go version. As with the go command, a go.work
+// file in the current or a parent directory takes precedence, unless
+// disabled with GOWORK=off.
+func detectVersionInGoMod(fs FileSystem) (requestedVersion, error) {
+	cwd, err := fs.Getwd()
+	if err != nil {
+		return requestedVersion{}, fmt.Errorf("could not get current directory: %w", err)
+	}
+
+	if workFile := findWorkFile(fs, cwd); workFile != "" {
+		return parseVersionFile(fs, workFile, true)
+	}
+
+	if modFile := findUp(fs, cwd, "go.mod"); modFile != "" {
+		return parseVersionFile(fs, modFile, false)
+	}
+
+	return requestedVersion{}, fmt.Errorf("no go.mod or go.work found in %s or any parent directory", cwd)
+}
+
+// findWorkFile locates the go.work file in effect for dir, if any
+func findWorkFile(fs FileSystem, dir string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "", "auto":
+		return findUp(fs, dir, "go.work")
+	default:
+		return gowork
+	}
+}
+
+// findUp looks for name in dir and each of its parents, returning
+// the path of the closest match or an empty string
+func findUp(fs FileSystem, dir, name string) string {
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := fs.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// parseVersionFile extracts the requested Go version from a go.mod or
+// go.work file. A toolchain directive takes precedence over the go
+// directive, matching the go command itself.
+func parseVersionFile(fs FileSystem, path string, isWork bool) (requestedVersion, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return requestedVersion{}, fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return requestedVersion{}, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	var goLine *modfile.Go
+	var toolchain *modfile.Toolchain
+	if isWork {
+		parsed, err := modfile.ParseWork(path, data, nil)
+		if err != nil {
+			return requestedVersion{}, fmt.Errorf("invalid go.work file: %w", err)
+		}
+		goLine, toolchain = parsed.Go, parsed.Toolchain
+	} else {
+		parsed, err := modfile.Parse(path, data, nil)
+		if err != nil {
+			return requestedVersion{}, fmt.Errorf("invalid go.mod file: %w", err)
+		}
+		goLine, toolchain = parsed.Go, parsed.Toolchain
+	}
+
+	// "toolchain default" means the go directive decides
+	if toolchain != nil && toolchain.Name != "default" {
+		return requestedVersion{
+			Version: strings.TrimPrefix(toolchain.Name, "go"),
+			Source:  "toolchain directive in " + path,
+		}, nil
+	}
+
+	if goLine != nil {
+		return requestedVersion{Version: goLine.Version, Source: "go directive in " + path}, nil
+	}
+
+	return requestedVersion{}, fmt.Errorf("no Go version found in %s", path)
+}
+
+// resolveRequestedVersion turns a requested version into a concrete
+// release. A release series, such as a go directive naming only a
+// language version (e.g. "go 1.22"), resolves to the newest installed
+// patch release of it, or the newest one available for download if
+// none is installed.
+func (m *VersionManager) resolveRequestedVersion(ctx context.Context, req requestedVersion, w io.Writer) (string, error) {
+	if !isMajorMinorVersion(strings.TrimPrefix(req.Version, "go")) {
+		fmt.Fprintf(w, "Detected Go %s from %s\n", req.Version, req.Source)
+		return req.Version, nil
+	}
+
+	installed, err := m.newestInstalled(req.Version)
+	if err != nil {
+		return "", err
+	}
+
+	if installed != "" {
+		fmt.Fprintf(w, "Detected Go %s from %s, using newest installed release %s\n", req.Version, req.Source, installed)
+		return installed, nil
+	}
+
+	latest, err := findLatestPatchVersion(strings.TrimPrefix(req.Version, "go"), false, m.source(ctx, w))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve Go %s from %s: %w", req.Version, req.Source, err)
+	}
+
+	fmt.Fprintf(w, "Detected Go %s from %s, using newest available release %s\n", req.Version, req.Source, latest)
+	return latest, nil
+}
+
+// newestInstalled returns the newest installed release of the
+// release series, or an empty string if none is installed
+func (m *VersionManager) newestInstalled(series string) (string, error) {
+	installed, err := m.installedVersions()
+	if err != nil {
+		return "", err
+	}
+
+	for _, v := range installed {
+		if matchesVersionFilter(v, series) && !isPrereleaseVersion(v) {
+			return v, nil
+		}
+	}
+
+	return "", nil
+}
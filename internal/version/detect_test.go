@@ -0,0 +1,326 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDetectVersionInGoMod(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected requestedVersion
+		wantErr  bool
+	}{
+		{
+			name:     "go directive",
+			content:  "module example.com/m\n\ngo 1.22.3\n",
+			expected: requestedVersion{Version: "1.22.3", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name:     "toolchain takes precedence",
+			content:  "module example.com/m\n\ngo 1.21\n\ntoolchain go1.22.3\n",
+			expected: requestedVersion{Version: "1.22.3", Source: "toolchain directive in /mock/project/go.mod"},
+		},
+		{
+			name:     "toolchain default",
+			content:  "module example.com/m\n\ngo 1.21\n\ntoolchain default\n",
+			expected: requestedVersion{Version: "1.21", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name:     "comments are ignored",
+			content:  "// go 1.18 was used before\nmodule example.com/m\n\ngo 1.22 // language version\n",
+			expected: requestedVersion{Version: "1.22", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name:     "go directive after require block",
+			content:  "module example.com/m\n\nrequire (\n\tgolang.org/x/mod v0.33.0\n)\n\ngo 1.24.2\n",
+			expected: requestedVersion{Version: "1.24.2", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name:    "no go directive",
+			content: "module example.com/m\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid go.mod",
+			content: "module example.com/m\n\ngo 1.22 extra\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFS := &MockFileSystem{
+				ExistingFiles: map[string]bool{"/mock/project/go.mod": true},
+				FileContents:  map[string]string{"/mock/project/go.mod": tc.content},
+			}
+
+			result, err := detectVersionInGoMod(mockFS)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("detectVersionInGoMod() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if result != tc.expected {
+				t.Errorf("detectVersionInGoMod() = %+v, want %+v", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDetectVersionInGoModSearch(t *testing.T) {
+	files := map[string]string{
+		"/mock/workspace/go.work":         "go 1.23.1\n\nuse ./lib\n",
+		"/mock/workspace/lib/go.mod":      "module example.com/lib\n\ngo 1.22.0\n",
+		"/mock/project/go.mod":            "module example.com/m\n\ngo 1.21.5\n",
+		"/mock/project/internal/pkg/a.go": "package pkg\n",
+	}
+
+	testCases := []struct {
+		name       string
+		workingDir string
+		gowork     string
+		expected   requestedVersion
+		wantErrMsg string
+	}{
+		{
+			name:       "module root",
+			workingDir: "/mock/project",
+			expected:   requestedVersion{Version: "1.21.5", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name:       "nested package",
+			workingDir: "/mock/project/internal/pkg",
+			expected:   requestedVersion{Version: "1.21.5", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name:       "go.work takes precedence",
+			workingDir: "/mock/workspace/lib",
+			expected:   requestedVersion{Version: "1.23.1", Source: "go directive in /mock/workspace/go.work"},
+		},
+		{
+			name:       "go.work disabled",
+			workingDir: "/mock/workspace/lib",
+			gowork:     "off",
+			expected:   requestedVersion{Version: "1.22.0", Source: "go directive in /mock/workspace/lib/go.mod"},
+		},
+		{
+			name:       "nothing found",
+			workingDir: "/mock/elsewhere",
+			wantErrMsg: "no go.mod or go.work found in /mock/elsewhere",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOWORK", tc.gowork)
+
+			mockFS := &MockFileSystem{
+				ExistingFiles: map[string]bool{},
+				FileContents:  files,
+				WorkingDir:    tc.workingDir,
+			}
+			for name := range files {
+				mockFS.ExistingFiles[name] = true
+			}
+
+			result, err := detectVersionInGoMod(mockFS)
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("detectVersionInGoMod() error = %v", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("detectVersionInGoMod() = %+v, want %+v", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDetectVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      string
+		files    map[string]string
+		expected requestedVersion
+	}{
+		{
+			name: "environment variable takes precedence",
+			env:  "1.20.1",
+			files: map[string]string{
+				"/mock/project/.gum-version": "1.21.0\n",
+				"/mock/project/go.mod":       "module example.com/m\n\ngo 1.22.0\n",
+			},
+			expected: requestedVersion{Version: "1.20.1", Source: "GUM_GO_VERSION"},
+		},
+		{
+			name: ".gum-version before .go-version",
+			files: map[string]string{
+				"/mock/project/.gum-version": "1.21.0\n",
+				"/mock/project/.go-version":  "1.20.0\n",
+				"/mock/project/go.mod":       "module example.com/m\n\ngo 1.22.0\n",
+			},
+			expected: requestedVersion{Version: "1.21.0", Source: "/mock/project/.gum-version"},
+		},
+		{
+			name: ".go-version in parent directory",
+			files: map[string]string{
+				"/mock/.go-version": "# pinned for CI\n\ngo1.20.0\n",
+				"/mock/go.mod":      "module example.com/m\n\ngo 1.22.0\n",
+			},
+			expected: requestedVersion{Version: "1.20.0", Source: "/mock/.go-version"},
+		},
+		{
+			name: "nearest pin file wins",
+			files: map[string]string{
+				"/mock/.gum-version":        "1.21.0\n",
+				"/mock/project/.go-version": "1.20.0\n",
+			},
+			expected: requestedVersion{Version: "1.20.0", Source: "/mock/project/.go-version"},
+		},
+		{
+			name: "go.mod before pin files of parent directories",
+			files: map[string]string{
+				"/mock/.gum-version":   "1.21.0\n",
+				"/mock/project/go.mod": "module example.com/m\n\ngo 1.22.0\n",
+			},
+			expected: requestedVersion{Version: "1.22.0", Source: "go directive in /mock/project/go.mod"},
+		},
+		{
+			name: "go.mod as fallback",
+			files: map[string]string{
+				"/mock/project/go.mod": "module example.com/m\n\ngo 1.22.0\n",
+			},
+			expected: requestedVersion{Version: "1.22.0", Source: "go directive in /mock/project/go.mod"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GUM_GO_VERSION", tc.env)
+			t.Setenv("GOWORK", "")
+
+			mockFS := &MockFileSystem{
+				ExistingFiles: map[string]bool{},
+				FileContents:  tc.files,
+			}
+			for name := range tc.files {
+				mockFS.ExistingFiles[name] = true
+			}
+
+			result, err := detectVersion(mockFS)
+			if err != nil {
+				t.Fatalf("detectVersion() error = %v", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("detectVersion() = %+v, want %+v", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestResolveRequestedVersion(t *testing.T) {
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			jsonContent := `[
+				{"version": "go1.23.4", "stable": true, "files": []},
+				{"version": "go1.22.5", "stable": true, "files": []},
+				{"version": "go1.22.4", "stable": true, "files": []}
+			]`
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(jsonContent)),
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		req        requestedVersion
+		expected   string
+		wantOutput string
+	}{
+		{
+			name:       "toolchain is used as is",
+			req:        requestedVersion{Version: "1.22.1", Source: "toolchain directive in go.mod"},
+			expected:   "1.22.1",
+			wantOutput: "Detected Go 1.22.1 from toolchain directive in go.mod",
+		},
+		{
+			name:       "concrete go version is used as is",
+			req:        requestedVersion{Version: "1.22.1", Source: "go directive in go.mod"},
+			expected:   "1.22.1",
+			wantOutput: "Detected Go 1.22.1 from go directive in go.mod",
+		},
+		{
+			name:       "language version prefers installed",
+			req:        requestedVersion{Version: "1.22", Source: "go directive in go.mod"},
+			expected:   "go1.22.4",
+			wantOutput: "using newest installed release go1.22.4",
+		},
+		{
+			name:       "pinned release series",
+			req:        requestedVersion{Version: "go1.22", Source: ".go-version"},
+			expected:   "go1.22.4",
+			wantOutput: "Detected Go go1.22 from .go-version, using newest installed release go1.22.4",
+		},
+		{
+			name:       "language version falls back to available",
+			req:        requestedVersion{Version: "1.23", Source: "go directive in go.mod"},
+			expected:   "go1.23.4",
+			wantOutput: "using newest available release go1.23.4",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFS := &MockFileSystem{
+				ExistingFiles: map[string]bool{
+					"/mock/home/.gum/versions":                  true,
+					"/mock/home/.gum/versions/go1.22.2":         true,
+					"/mock/home/.gum/versions/go1.22.2/bin/go":  true,
+					"/mock/home/.gum/versions/go1.22.4":         true,
+					"/mock/home/.gum/versions/go1.22.4/bin/go":  true,
+					"/mock/home/.gum/versions/go1.22rc1":        true,
+					"/mock/home/.gum/versions/go1.22rc1/bin/go": true,
+				},
+			}
+
+			manager := &VersionManager{
+				fs:         mockFS,
+				httpClient: mockHTTP,
+				installDir: "/mock/home/.gum/versions",
+				binDir:     "/mock/home/.gum/bin",
+			}
+
+			var buf bytes.Buffer
+			result, err := manager.resolveRequestedVersion(context.Background(), tc.req, &buf)
+			if err != nil {
+				t.Fatalf("resolveRequestedVersion() error = %v", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("resolveRequestedVersion(%+v) = %q, want %q", tc.req, result, tc.expected)
+			}
+
+			if !strings.Contains(buf.String(), tc.wantOutput) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tc.wantOutput, buf.String())
+			}
+		})
+	}
+}
@@ -1,20 +1,29 @@
 package version
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
+
+	"golang.org/x/mod/sumdb/dirhash"
 )
 
 const (
-	BaseURL = "https://go.dev/dl"
+	defaultDownloadURL = "https://go.dev/dl"
 )
 
-func getDownloadURL(v string) (string, error) {
+func getDownloadURL(baseURL, v string) (string, error) {
 	installOS := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -35,44 +44,55 @@ func getDownloadURL(v string) (string, error) {
 	switch installOS {
 	case "darwin":
 		if arch == "amd64" || arch == "arm64" {
-			return fmt.Sprintf("%s/%s.darwin-%s.tar.gz", BaseURL, v, archName), nil
+			return fmt.Sprintf("%s/%s.darwin-%s.tar.gz", baseURL, v, archName), nil
 		}
 		return "", fmt.Errorf("unsupported macOS architecture: %s", archName)
 	case "linux":
-		return fmt.Sprintf("%s/%s.%s-%s.tar.gz", BaseURL, v, installOS, archName), nil
+		return fmt.Sprintf("%s/%s.%s-%s.tar.gz", baseURL, v, installOS, archName), nil
 	default:
 		return "", fmt.Errorf("OS not supported by gum: %s", installOS)
 	}
 }
 
-func downloadAndExtract(url, destDir string, w io.Writer, client HTTPClient) error {
-	tmpFile, err := os.CreateTemp("", "gum-download-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
-	}
+// downloadAndExtract downloads archive a, verifies it and extracts it
+// into destDir. With a cacheDir, a verified copy of the archive is
+// kept there and reused by later calls.
+func downloadAndExtract(ctx context.Context, a archive, destDir, cacheDir string, w io.Writer, client HTTPClient) error {
+	cachePath := cachedArchivePath(cacheDir, a)
 
-	// Clean up temp file deferred
-	defer tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
+	archivePath := ""
+	if cachePath != "" && useCachedArchive(a, cachePath, w) {
+		archivePath = cachePath
+	}
 
-	if err := downloadFile(url, tmpFile, w, client); err != nil {
-		return err
+	if archivePath == "" {
+		var err error
+		if archivePath, err = downloadArchive(ctx, a, cachePath, w, client); err != nil {
+			return err
+		}
+		if archivePath != cachePath {
+			defer os.Remove(archivePath)
+		}
 	}
 
-	// Move pointer to beginning of file
-	if _, err := tmpFile.Seek(0, 0); err != nil {
+	file, err := os.Open(archivePath)
+	if err != nil {
 		return fmt.Errorf("failed to prepare for extraction: %w", err)
 	}
+	defer file.Close()
 
 	if err := os.MkdirAll(filepath.Dir(destDir), 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	fmt.Fprintf(w, "Extracting to %s...\n", destDir)
-	if strings.HasSuffix(url, ".tar.gz") {
-		err = extractTarGz(tmpFile, destDir)
-	} else {
-		err = fmt.Errorf("unsupported archive format: %s", url)
+	switch {
+	case strings.HasSuffix(a.url, ".tar.gz"):
+		err = extractTarGz(ctx, file, destDir)
+	case strings.HasSuffix(a.url, ".zip"):
+		err = extractToolchainZip(ctx, file, destDir)
+	default:
+		err = fmt.Errorf("unsupported archive format: %s", a.url)
 	}
 
 	if err != nil {
@@ -82,34 +102,305 @@ func downloadAndExtract(url, destDir string, w io.Writer, client HTTPClient) err
 	return nil
 }
 
-func downloadFile(url string, file *os.File, w io.Writer, client HTTPClient) error {
-	req, err := http.NewRequest("GET", url, nil)
+// useCachedArchive reports whether the copy of a at cachePath can be
+// used. Copies that no longer match their checksum are removed.
+func useCachedArchive(a archive, cachePath string, w io.Writer) bool {
+	if _, err := os.Stat(cachePath); err != nil {
+		return false
+	}
+
+	if err := verifyArchive(a, cachePath, "", io.Discard); err != nil {
+		fmt.Fprintf(w, "Discarding cached %s: %v\n", cachePath, err)
+		os.Remove(cachePath)
+		return false
+	}
+
+	// The modification time records when the archive was last used
+	now := time.Now()
+	os.Chtimes(cachePath, now, now)
+
+	fmt.Fprintf(w, "Using cached %s\n", cachePath)
+	return true
+}
+
+// downloadArchive downloads and verifies a, and returns where it is.
+// That is cachePath if set, or a temporary file otherwise.
+func downloadArchive(ctx context.Context, a archive, cachePath string, w io.Writer, client HTTPClient) (string, error) {
+	var file *os.File
+	if cachePath == "" {
+		tmpFile, err := os.CreateTemp("", downloadPrefix+"*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		file = tmpFile
+	} else {
+		// Downloads go next to their place in the cache, so moving them
+		// there can not leave a partial file behind. What an interrupted
+		// download leaves is picked up by the next one.
+		dir := filepath.Dir(cachePath)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create cache directory: %w", err)
+		}
+
+		partial, err := os.OpenFile(filepath.Join(dir, downloadPrefix+filepath.Base(cachePath)), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		file = partial
+	}
+	defer file.Close()
+
+	sum, err := downloadFile(ctx, a.url, file, w, client)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		if cachePath == "" {
+			os.Remove(file.Name())
+		}
+		return "", err
+	}
+
+	if err := verifyArchive(a, file.Name(), sum, w); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	if cachePath == "" {
+		return file.Name(), nil
+	}
+
+	if err := os.Rename(file.Name(), cachePath); err != nil {
+		fmt.Fprintf(w, "Warning: failed to cache %s: %v\n", a.url, err)
+		return file.Name(), nil
+	}
+	return cachePath, nil
+}
+
+// verifyArchive checks the archive at path against the checksum of a.
+// sum is the SHA-256 checksum of the file, if it is known already.
+// Never extract an archive that could not be verified.
+func verifyArchive(a archive, path, sum string, w io.Writer) error {
+	switch {
+	case a.sha256 != "":
+		if sum == "" {
+			var err error
+			if sum, err = fileSHA256(path); err != nil {
+				return err
+			}
+		}
+		if !strings.EqualFold(sum, a.sha256) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", a.url, a.sha256, sum)
+		}
+		fmt.Fprintf(w, "Verified SHA-256 checksum %s\n", sum)
+	case a.h1 != "":
+		h1, err := dirhash.HashZip(path, dirhash.Hash1)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", a.url, err)
+		}
+		if h1 != a.h1 {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", a.url, a.h1, h1)
+		}
+		fmt.Fprintf(w, "Verified checksum %s from the Go checksum database\n", h1)
+	default:
+		return fmt.Errorf("no checksum to verify %s with", a.url)
+	}
+
+	return nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of the file at path
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+const (
+	// maxDownloadAttempts bounds how often a download is retried
+	maxDownloadAttempts = 5
+	// retryBaseDelay is the delay before the first retry, doubling
+	// with every further attempt up to retryMaxDelay
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 15 * time.Second
+)
+
+// retrySleep waits between download attempts, replaced in tests
+var retrySleep = sleepContext
+
+// sleepContext waits for d, or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// downloadFile writes the body at url to file and returns the hex
+// encoded SHA-256 checksum of the file. Bytes already in file are
+// kept, and only the rest is requested. Transient failures are retried
+// with exponential backoff, resuming where the last attempt stopped.
+func downloadFile(ctx context.Context, url string, file *os.File, w io.Writer, client HTTPClient) (string, error) {
+	digest := sha256.New()
+	offset, err := io.Copy(digest, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read partial download: %w", err)
+	}
+
+	// validator makes sure resumed bytes belong to the same file
+	validator := ""
+	for attempt := 1; ; attempt++ {
+		var err error
+		validator, err = downloadRange(ctx, url, file, offset, validator, digest, w, client)
+		if err == nil {
+			break
+		}
+
+		var restartErr *restartError
+		if errors.As(err, &restartErr) {
+			// The partial download is of no use, start over
+			if err := restartDownload(file, digest); err != nil {
+				return "", err
+			}
+			validator = ""
+		} else if !isTransient(err) || ctx.Err() != nil {
+			return "", err
+		}
+
+		if attempt == maxDownloadAttempts {
+			return "", fmt.Errorf("download failed after %d attempts: %w", attempt, err)
+		}
+
+		if offset, err = file.Seek(0, io.SeekCurrent); err != nil {
+			return "", fmt.Errorf("failed to resume download: %w", err)
+		}
+
+		delay := retryDelay(attempt)
+		fmt.Fprintf(w, "\nDownload interrupted: %v\nRetrying in %s (attempt %d of %d)...\n", err, delay.Round(time.Millisecond), attempt+1, maxDownloadAttempts)
+		if err := retrySleep(ctx, delay); err != nil {
+			return "", err
+		}
+	}
+
+	// New line
+	fmt.Fprintln(w)
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// downloadRange requests the bytes of url from offset on and appends
+// them to file and digest. validator is the ETag or modification time
+// the bytes before offset were received with, if known. It returns the
+// validator of the response.
+func downloadRange(ctx context.Context, url string, file *os.File, offset int64, validator string, digest hash.Hash, w io.Writer, client HTTPClient) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "gum/1.0")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+		return validator, &transientError{fmt.Errorf("failed to download: %w", err)}
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status %s", resp.Status)
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		validator = etag
+	} else if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		validator = modified
 	}
 
-	progress := newProgressWriter(w, resp.ContentLength)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != offset {
+			return validator, &restartError{fmt.Errorf("server resumed at the wrong offset: %q", resp.Header.Get("Content-Range"))}
+		}
+		fmt.Fprintf(w, "Resuming download at %s\n", formatBytes(offset))
+	case resp.StatusCode == http.StatusOK:
+		if offset > 0 {
+			// The server sent the whole file again
+			if err := restartDownload(file, digest); err != nil {
+				return validator, err
+			}
+			offset = 0
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		return validator, &restartError{fmt.Errorf("download failed with status %s", resp.Status)}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return validator, &transientError{fmt.Errorf("download failed with status %s", resp.Status)}
+	default:
+		return validator, fmt.Errorf("download failed with status %s", resp.Status)
+	}
 
-	_, err = io.Copy(file, io.TeeReader(resp.Body, progress))
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
 	}
+	progress := newProgressWriter(w, offset, total)
 
-	// New line
-	fmt.Fprintln(w)
+	if _, err := io.Copy(io.MultiWriter(file, digest), io.TeeReader(resp.Body, progress)); err != nil {
+		return validator, &transientError{fmt.Errorf("download failed: %w", err)}
+	}
+
+	return validator, nil
+}
 
+// restartDownload discards everything downloaded into file so far
+func restartDownload(file *os.File, digest hash.Hash) error {
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to restart download: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to restart download: %w", err)
+	}
+	digest.Reset()
 	return nil
 }
+
+// transientError is a download failure that may go away when retried
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// restartError is a download failure that requires starting over
+type restartError struct {
+	err error
+}
+
+func (e *restartError) Error() string { return e.err.Error() }
+func (e *restartError) Unwrap() error { return e.err }
+
+func isTransient(err error) bool {
+	var transientErr *transientError
+	return errors.As(err, &transientErr)
+}
+
+// retryDelay returns how long to wait before retrying after attempt,
+// with jitter so parallel downloads do not retry in lockstep
+func retryDelay(attempt int) time.Duration {
+	delay := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	return delay/2 + rand.N(delay/2)
+}
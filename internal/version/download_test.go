@@ -2,14 +2,32 @@ package version
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
+// noRetryDelay makes failed downloads retry right away
+func noRetryDelay(t *testing.T) {
+	t.Helper()
+
+	original := retrySleep
+	retrySleep = func(context.Context, time.Duration) error { return nil }
+	t.Cleanup(func() { retrySleep = original })
+}
+
 func TestDownloadFile(t *testing.T) {
+	noRetryDelay(t)
+
 	tests := []struct {
 		name       string
 		url        string
@@ -78,11 +96,11 @@ func TestDownloadFile(t *testing.T) {
 
 			// Capture output
 			var buf bytes.Buffer
-			err = downloadFile(tt.url, tmpFile, &buf, mockHTTP)
+			sum, err := downloadFile(context.Background(), tt.url, tmpFile, &buf, mockHTTP)
 
 			// Check error expectations
 			if (err != nil) != tt.wantErr {
-				t.Errorf("downloadFile() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("downloadFile(context.Background(), ) error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
@@ -102,6 +120,11 @@ func TestDownloadFile(t *testing.T) {
 					t.Errorf("File content = %v, want %v", string(content), tt.httpBody)
 				}
 
+				expectedSum := sha256.Sum256([]byte(tt.httpBody))
+				if sum != hex.EncodeToString(expectedSum[:]) {
+					t.Errorf("Checksum = %v, want %x", sum, expectedSum)
+				}
+
 				// The progress info check isn't reliable in tests with small content
 				// so we'll just verify some output was produced
 				if buf.Len() == 0 {
@@ -111,3 +134,274 @@ func TestDownloadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloadAndExtractChecksumMismatch(t *testing.T) {
+	destDir := t.TempDir()
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("tampered archive")),
+			}, nil
+		},
+	}
+
+	var buf bytes.Buffer
+	err := downloadAndExtract(context.Background(), archive{url: "https://example.com/go1.24.2.linux-amd64.tar.gz", sha256: "deadbeef"}, destDir+"/go1.24.2", "", &buf, mockHTTP)
+	if err == nil {
+		t.Fatal("Expected checksum mismatch error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected error to contain 'checksum mismatch', got: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "Extracting") {
+		t.Errorf("Expected no extraction after checksum mismatch, got '%s'", buf.String())
+	}
+}
+
+// flakyReader fails after returning limit bytes of data
+type flakyReader struct {
+	data  []byte
+	limit int
+	read  int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.read >= len(r.data) {
+		return 0, io.EOF
+	}
+	if r.read >= r.limit {
+		return 0, errors.New("connection reset by peer")
+	}
+	n := copy(p, r.data[r.read:min(len(r.data), r.limit)])
+	r.read += n
+	return n, nil
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	noRetryDelay(t)
+
+	content := []byte(strings.Repeat("0123456789", 100))
+	expectedSum := sha256.Sum256(content)
+
+	testCases := []struct {
+		name string
+		// partial is already in the file before downloading
+		partial string
+		// responses are the status codes returned in turn. The first
+		// response fails after failAfter bytes of the archive.
+		responses     []int
+		failAfter     int
+		ignoreRange   bool
+		wantRanges    []string
+		wantErrMsg    string
+		wantResumeMsg bool
+	}{
+		{
+			name:          "interrupted download resumes",
+			responses:     []int{http.StatusOK, http.StatusPartialContent},
+			failAfter:     300,
+			wantRanges:    []string{"", "bytes=300-"},
+			wantResumeMsg: true,
+		},
+		{
+			name:          "partial file resumes",
+			partial:       string(content[:500]),
+			responses:     []int{http.StatusPartialContent},
+			failAfter:     len(content),
+			wantRanges:    []string{"bytes=500-"},
+			wantResumeMsg: true,
+		},
+		{
+			name:        "server without range support",
+			partial:     string(content[:500]),
+			responses:   []int{http.StatusOK},
+			failAfter:   len(content),
+			ignoreRange: true,
+			wantRanges:  []string{"bytes=500-"},
+		},
+		{
+			name:       "server errors are retried",
+			responses:  []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			failAfter:  len(content),
+			wantRanges: []string{"", "", ""},
+		},
+		{
+			name:       "partial file larger than the archive",
+			partial:    string(content) + "trailing",
+			responses:  []int{http.StatusRequestedRangeNotSatisfiable, http.StatusOK},
+			failAfter:  len(content),
+			wantRanges: []string{"bytes=1008-", ""},
+		},
+		{
+			name:       "client errors are not retried",
+			responses:  []int{http.StatusForbidden},
+			wantRanges: []string{""},
+			wantErrMsg: "status 403",
+		},
+		{
+			name:       "too many failures",
+			responses:  []int{500, 500, 500, 500, 500, 500},
+			wantRanges: []string{"", "", "", "", ""},
+			wantErrMsg: "after 5 attempts",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "download")
+			if err := os.WriteFile(path, []byte(tc.partial), 0644); err != nil {
+				t.Fatalf("Failed to write partial download: %v", err)
+			}
+			file, err := os.OpenFile(path, os.O_RDWR, 0644)
+			if err != nil {
+				t.Fatalf("Failed to open partial download: %v", err)
+			}
+			defer file.Close()
+
+			var ranges []string
+			mockHTTP := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					ranges = append(ranges, req.Header.Get("Range"))
+					status := tc.responses[len(ranges)-1]
+
+					resp := &http.Response{
+						StatusCode:    status,
+						Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+						Header:        http.Header{"Etag": []string{`"archive"`}},
+						Body:          io.NopCloser(strings.NewReader("")),
+						ContentLength: -1,
+					}
+
+					start := 0
+					if status == http.StatusPartialContent {
+						if tc.ignoreRange {
+							t.Fatal("Unexpected partial response")
+						}
+						if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-", &start); err != nil {
+							t.Fatalf("Unexpected Range header %q", req.Header.Get("Range"))
+						}
+						if req.Header.Get("If-Range") != "" && req.Header.Get("If-Range") != `"archive"` {
+							t.Errorf("Unexpected If-Range header %q", req.Header.Get("If-Range"))
+						}
+						resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+					}
+					if status == http.StatusOK || status == http.StatusPartialContent {
+						limit := len(content)
+						if len(ranges) == 1 {
+							limit = tc.failAfter
+						}
+						resp.ContentLength = int64(len(content) - start)
+						resp.Body = io.NopCloser(&flakyReader{data: content[start:], limit: limit - start})
+					}
+					return resp, nil
+				},
+			}
+
+			var buf bytes.Buffer
+			sum, err := downloadFile(context.Background(), "https://example.com/go.tar.gz", file, &buf, mockHTTP)
+
+			if strings.Join(ranges, ",") != strings.Join(tc.wantRanges, ",") {
+				t.Errorf("Requested ranges %q, want %q", ranges, tc.wantRanges)
+			}
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("downloadFile(context.Background(), ) error = %v, output = %s", err, buf.String())
+			}
+			if sum != hex.EncodeToString(expectedSum[:]) {
+				t.Errorf("Checksum = %v, want %x", sum, expectedSum)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read download: %v", err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("Downloaded %d bytes, want the %d bytes of the archive", len(got), len(content))
+			}
+
+			if resumed := strings.Contains(buf.String(), "Resuming download at"); resumed != tc.wantResumeMsg {
+				t.Errorf("Expected resume message %v, got output '%s'", tc.wantResumeMsg, buf.String())
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		full := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+		for range 20 {
+			if delay := retryDelay(attempt); delay < full/2 || delay >= full {
+				t.Errorf("retryDelay(%d) = %s, want between %s and %s", attempt, delay, full/2, full)
+			}
+		}
+	}
+}
+
+func TestDownloadArchiveResumesPartialDownload(t *testing.T) {
+	noRetryDelay(t)
+
+	content := []byte(strings.Repeat("0123456789", 100))
+	checksum := sha256.Sum256(content)
+	a := archive{url: "https://example.com/go1.24.2.linux-amd64.tar.gz", sha256: hex.EncodeToString(checksum[:])}
+	cachePath := cachedArchivePath(t.TempDir(), a)
+
+	// Every attempt of the first run breaks off after 400 bytes
+	online := false
+	var ranges []string
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			ranges = append(ranges, req.Header.Get("Range"))
+			if !online {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(&flakyReader{data: content, limit: 400}),
+				}, nil
+			}
+
+			var start int
+			fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-", &start)
+			return &http.Response{
+				StatusCode:    http.StatusPartialContent,
+				Header:        http.Header{"Content-Range": []string{fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content))}},
+				Body:          io.NopCloser(bytes.NewReader(content[start:])),
+				ContentLength: int64(len(content) - start),
+			}, nil
+		},
+	}
+
+	if _, err := downloadArchive(context.Background(), a, cachePath, io.Discard, mockHTTP); err == nil {
+		t.Fatal("Expected the first download to fail")
+	}
+
+	partial := filepath.Join(filepath.Dir(cachePath), downloadPrefix+filepath.Base(cachePath))
+	if info, err := os.Stat(partial); err != nil || info.Size() == 0 {
+		t.Fatalf("Expected a partial download to be kept, got %v", err)
+	}
+
+	online = true
+	ranges = nil
+	path, err := downloadArchive(context.Background(), a, cachePath, io.Discard, mockHTTP)
+	if err != nil {
+		t.Fatalf("downloadArchive(context.Background(), ) error = %v", err)
+	}
+	if path != cachePath {
+		t.Errorf("downloadArchive(context.Background(), ) = %s, want %s", path, cachePath)
+	}
+	if len(ranges) != 1 || ranges[0] == "" {
+		t.Errorf("Expected the download to resume with one request, got ranges %q", ranges)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("Expected the partial download to be moved into the cache, got %v", err)
+	}
+}
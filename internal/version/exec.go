@@ -0,0 +1,99 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stopGracePeriod is how long a command gets to exit after it was
+// asked to stop, before it is killed
+const stopGracePeriod = 5 * time.Second
+
+// Exec runs command with Go version v first on PATH and GOROOT set,
+// leaving the active version alone. Messages from gum itself go to
+// stderr so they never mix with the output of the command. If the
+// command fails, the returned error wraps its *exec.ExitError.
+func (m *VersionManager) Exec(ctx context.Context, v string, command []string, opts ExecOptions, stdout, stderr io.Writer) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no command provided")
+	}
+
+	v, err := m.execVersion(ctx, v, opts.Install, stderr)
+	if err != nil {
+		return err
+	}
+
+	cmd := m.toolchainCommand(ctx, v, command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed under Go %s: %w", command[0], v, err)
+	}
+
+	return nil
+}
+
+// execVersion finds the installed version v refers to, installing it
+// first if install is set. A release series, such as 1.22 or 1, uses
+// its newest installed release, see resolveSpec.
+func (m *VersionManager) execVersion(ctx context.Context, v string, install bool, w io.Writer) (string, error) {
+	if strings.TrimSpace(v) == "" {
+		return "", fmt.Errorf("no version provided")
+	}
+
+	v, err := m.resolveSpec(ctx, v, resolveOptions{preferInstalled: true}, m.source(ctx, w), w)
+	if err != nil {
+		return "", err
+	}
+
+	v = normaliseVersion(v)
+	if m.isComplete(filepath.Join(m.installDir, v)) {
+		return v, nil
+	}
+
+	if !install && !m.autoInstall {
+		return "", fmt.Errorf("Go %s is not installed. Use 'gum install %s' first, or pass --install", v, v)
+	}
+
+	return m.installMissing(ctx, v, w)
+}
+
+// toolchainCommand prepares command to run with the installed version
+// v first on PATH and GOROOT set. When ctx is cancelled, the command
+// is asked to stop, and killed if it is still running after
+// stopGracePeriod.
+func (m *VersionManager) toolchainCommand(ctx context.Context, v string, command []string) *exec.Cmd {
+	versionDir := filepath.Join(m.installDir, v)
+	binDir := filepath.Join(versionDir, "bin")
+	path := append([]string{binDir}, m.pathWithoutVersions()...)
+
+	env := os.Environ()
+	env = setEnv(env, "GOROOT", versionDir)
+	env = setEnv(env, "PATH", strings.Join(path, string(os.PathListSeparator)))
+	// Keep go from switching to the toolchain a go.mod asks for,
+	// which would defeat running under a specific version
+	env = setEnv(env, "GOTOOLCHAIN", "local")
+
+	// exec.Command looks names up on our own PATH, so tools of the
+	// version are found explicitly
+	name := command[0]
+	if !strings.ContainsRune(name, filepath.Separator) {
+		if _, err := m.fs.Stat(filepath.Join(binDir, name)); err == nil {
+			name = filepath.Join(binDir, name)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, name, command[1:]...)
+	cmd.Env = env
+	cmd.Cancel = func() error { return stopProcess(cmd.Process) }
+	cmd.WaitDelay = stopGracePeriod
+	return cmd
+}
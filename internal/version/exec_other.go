@@ -0,0 +1,20 @@
+//go:build !unix
+
+package version
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// execTool replaces the current process with the tool at path
+func execTool(path string, args, env []string) error {
+	return fmt.Errorf("shims are not supported on %s", runtime.GOOS)
+}
+
+// stopProcess ends p. Other platforms have no signal to ask a process
+// to exit, so it is killed right away.
+func stopProcess(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,150 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVersionManager_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test tools are shell scripts")
+	}
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"versions/go1.21.0/bin/go": "#!/bin/sh\necho go1.21.0\n",
+		"versions/go1.22.2/bin/go": "#!/bin/sh\necho go1.22.2\n",
+		"versions/go1.22.4/bin/go": "#!/bin/sh\necho \"go1.22.4 $GOROOT $GOTOOLCHAIN\"\nexit \"${1:-0}\"\n",
+	})
+	installDir := filepath.Join(root, "versions")
+
+	testCases := []struct {
+		name         string
+		version      string
+		command      []string
+		wantOutput   string
+		wantExitCode int
+		wantErrMsg   string
+	}{
+		{
+			name:       "concrete version",
+			version:    "1.21.0",
+			command:    []string{"go"},
+			wantOutput: "go1.21.0\n",
+		},
+		{
+			name:       "release series uses newest installed",
+			version:    "1.22",
+			command:    []string{"go"},
+			wantOutput: "go1.22.4 " + filepath.Join(installDir, "go1.22.4") + " local\n",
+		},
+		{
+			name:       "version is first on PATH",
+			version:    "go1.21.0",
+			command:    []string{"sh", "-c", "go"},
+			wantOutput: "go1.21.0\n",
+		},
+		{
+			name:         "exit code is kept",
+			version:      "1.22.4",
+			command:      []string{"go", "3"},
+			wantExitCode: 3,
+		},
+		{
+			name:       "version not installed",
+			version:    "1.20.0",
+			command:    []string{"go"},
+			wantErrMsg: "Go go1.20.0 is not installed",
+		},
+		{
+			name:       "no command",
+			version:    "1.21.0",
+			wantErrMsg: "no command provided",
+		},
+		{
+			name:       "empty version",
+			version:    " ",
+			command:    []string{"go"},
+			wantErrMsg: "no version provided",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("PATH", filepath.Join(installDir, "go1.22.4", "bin")+":/usr/bin:/bin")
+
+			manager := &VersionManager{
+				fs:         OSFileSystem{},
+				installDir: installDir,
+			}
+
+			var stdout, stderr bytes.Buffer
+			err := manager.Exec(context.Background(), tc.version, tc.command, ExecOptions{}, &stdout, &stderr)
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if tc.wantExitCode != 0 {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					t.Fatalf("Expected an exit error, got %v", err)
+				}
+				if exitErr.ExitCode() != tc.wantExitCode {
+					t.Errorf("Exit code = %d, want %d", exitErr.ExitCode(), tc.wantExitCode)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VersionManager.Exec() error = %v", err)
+			}
+
+			if stdout.String() != tc.wantOutput {
+				t.Errorf("Output = %q, want %q", stdout.String(), tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestVersionManager_ExecCancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test tools are shell scripts")
+	}
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"versions/go1.22.4/bin/go": "#!/bin/sh\ntrap 'kill $!; echo stopped; exit 143' TERM\nsleep 10 >/dev/null 2>&1 &\nwait\n",
+	})
+
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		installDir: filepath.Join(root, "versions"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var stdout, stderr bytes.Buffer
+	err := manager.Exec(ctx, "1.22.4", []string{"go"}, ExecOptions{}, &stdout, &stderr)
+	if err == nil {
+		t.Fatal("Expected an error for the cancelled command")
+	}
+	if elapsed := time.Since(start); elapsed > stopGracePeriod {
+		t.Errorf("Expected the command to stop when cancelled, took %s", elapsed)
+	}
+	if stdout.String() != "stopped\n" {
+		t.Errorf("Expected the command to be asked to stop, got output %q", stdout.String())
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package version
+
+import (
+	"os"
+	"syscall"
+)
+
+// execTool replaces the current process with the tool at path
+func execTool(path string, args, env []string) error {
+	return syscall.Exec(path, append([]string{path}, args...), env)
+}
+
+// stopProcess asks p to exit, giving it the chance to clean up
+func stopProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
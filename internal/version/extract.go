@@ -2,22 +2,41 @@ package version
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
+	"context"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
-func extractTarGz(file *os.File, destDir string) error {
-	gzr, err := gzip.NewReader(file)
+// extractTarGz extracts a Go release archive into destDir, stripping
+// the leading go/ folder. Entries that would end up outside destDir,
+// either directly or through a link, are rejected. Extraction stops
+// between entries once ctx is cancelled.
+func extractTarGz(ctx context.Context, r io.Reader, destDir string) error {
+	gzr, err := gzip.NewReader(r)
 	if err != nil {
 		return err
 	}
 	defer gzr.Close()
 
+	destDir = filepath.Clean(destDir)
 	tr := tar.NewReader(gzr)
 
+	// Directory times are applied last, since extracting
+	// files into a directory updates its modification time
+	dirTimes := map[string]time.Time{}
+
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		header, err := tr.Next()
 		if err == io.EOF {
 			break
@@ -31,18 +50,21 @@ func extractTarGz(file *os.File, destDir string) error {
 			continue
 		}
 
-		targetPath := header.Name
-		if len(targetPath) > 3 && targetPath[:3] == "go/" {
-			targetPath = targetPath[3:]
+		targetPath, err := archiveEntryPath(destDir, header.Name)
+		if err != nil {
+			return fmt.Errorf("rejected archive entry %q: %w", header.Name, err)
 		}
 
-		targetPath = filepath.Join(destDir, targetPath)
+		if err := checkNoSymlinkParents(destDir, targetPath); err != nil {
+			return fmt.Errorf("rejected archive entry %q: %w", header.Name, err)
+		}
 
 		// Create folders
 		if header.Typeflag == tar.TypeDir {
 			if err := os.MkdirAll(targetPath, 0755); err != nil {
 				return err
 			}
+			dirTimes[targetPath] = header.ModTime
 			continue
 		}
 
@@ -51,20 +73,280 @@ func extractTarGz(file *os.File, destDir string) error {
 			return err
 		}
 
-		// Create file
-		f, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+		// Never write through something left behind by an earlier entry
+		if err := removeExisting(targetPath); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			if err := writeFile(tr, targetPath, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := checkLinkTarget(destDir, targetPath, header.Linkname); err != nil {
+				return fmt.Errorf("rejected symlink %q: %w", header.Name, err)
+			}
+			if err := os.Symlink(header.Linkname, targetPath); err != nil {
+				return err
+			}
+			// Symlink times can not be set portably
+			continue
+		case tar.TypeLink:
+			linkPath, err := archiveEntryPath(destDir, header.Linkname)
+			if err != nil {
+				return fmt.Errorf("rejected hardlink %q: target %q: %w", header.Name, header.Linkname, err)
+			}
+			if err := checkNoSymlinkParents(destDir, linkPath); err != nil {
+				return fmt.Errorf("rejected hardlink %q: target %q: %w", header.Name, header.Linkname, err)
+			}
+			info, err := os.Lstat(linkPath)
+			if err != nil {
+				return fmt.Errorf("rejected hardlink %q: target %q not found", header.Name, header.Linkname)
+			}
+			if !info.Mode().IsRegular() {
+				return fmt.Errorf("rejected hardlink %q: target %q is not a regular file", header.Name, header.Linkname)
+			}
+			if err := os.Link(linkPath, targetPath); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("rejected archive entry %q: unsupported type %q", header.Name, header.Typeflag)
+		}
+
+		if err := os.Chtimes(targetPath, header.ModTime, header.ModTime); err != nil {
+			return err
+		}
+	}
+
+	for dir, modTime := range dirTimes {
+		if err := os.Chtimes(dir, modTime, modTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractToolchainZip extracts a golang.org/toolchain module zip into
+// destDir, stripping the leading module@version folder. Module zips
+// only hold regular files and do not record whether they are
+// executable, so the tools in bin and pkg/tool are made executable,
+// as the go command does.
+func extractToolchainZip(ctx context.Context, file *os.File, destDir string) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return err
+	}
+
+	destDir = filepath.Clean(destDir)
+
+	for _, entry := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Entries are named golang.org/toolchain@<version>/<path>
+		rest, ok := strings.CutPrefix(entry.Name, toolchainModule+"@")
+		if !ok {
+			return fmt.Errorf("rejected archive entry %q: outside of the module", entry.Name)
+		}
+		_, name, _ := strings.Cut(rest, "/")
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+
+		targetPath, err := archiveEntryPath(destDir, name)
+		if err != nil {
+			return fmt.Errorf("rejected archive entry %q: %w", entry.Name, err)
+		}
+		if !entry.Mode().IsRegular() {
+			return fmt.Errorf("rejected archive entry %q: unsupported type %s", entry.Name, entry.Mode().Type())
+		}
+
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+			return err
+		}
+
+		perm := os.FileMode(0644)
+		if isToolchainTool(name) || entry.Mode().Perm()&0111 != 0 {
+			perm = 0755
+		}
+
+		r, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(r, targetPath, perm)
+		r.Close()
 		if err != nil {
 			return err
 		}
 
-		// Copy content
-		if _, err := io.Copy(f, tr); err != nil {
-			f.Close()
+		if err := os.Chtimes(targetPath, entry.Modified, entry.Modified); err != nil {
 			return err
 		}
+	}
 
-		f.Close()
+	return nil
+}
+
+// isToolchainTool reports whether name, relative to GOROOT, is an
+// executable of the toolchain
+func isToolchainTool(name string) bool {
+	dir := path.Dir(name)
+	return dir == "bin" || strings.HasPrefix(dir, "pkg/tool/")
+}
+
+// archiveEntryPath maps an archive entry name to its location in destDir
+func archiveEntryPath(destDir, name string) (string, error) {
+	name = strings.TrimPrefix(name, "go/")
+
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("absolute path")
+	}
+
+	targetPath := filepath.Join(destDir, name)
+	if !isWithin(destDir, targetPath) {
+		return "", fmt.Errorf("path escapes destination")
+	}
+
+	return targetPath, nil
+}
+
+// maxLinkHops is how many links a symlink target may pass through
+const maxLinkHops = 40
+
+// checkLinkTarget makes sure a symlink at linkPath pointing to
+// target resolves to a location inside destDir, following the links
+// extracted before it
+func checkLinkTarget(destDir, linkPath, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("absolute target %q", target)
+	}
+
+	hops := 0
+	if _, err := resolveLinkTarget(destDir, filepath.Dir(linkPath), target, &hops); err != nil {
+		return fmt.Errorf("target %q %w", target, err)
+	}
+
+	return nil
+}
+
+// resolveLinkTarget resolves target relative to dir the way the OS
+// would, following links already in destDir. Every step has to stay
+// inside destDir. A .. is only allowed after a directory, since
+// anything else may be replaced by a link later on, which would move
+// where .. leads.
+func resolveLinkTarget(destDir, dir, target string, hops *int) (string, error) {
+	current := dir
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if info, err := os.Lstat(current); err != nil || !info.IsDir() {
+				return "", fmt.Errorf("passes through %q, which is not a directory", current)
+			}
+			current = filepath.Dir(current)
+		default:
+			current = filepath.Join(current, part)
+		}
+
+		if !isWithin(destDir, current) {
+			return "", fmt.Errorf("escapes destination")
+		}
+
+		info, err := os.Lstat(current)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		*hops++
+		if *hops > maxLinkHops {
+			return "", fmt.Errorf("passes through too many links")
+		}
+		link, err := os.Readlink(current)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(link) {
+			return "", fmt.Errorf("passes through absolute link %q", current)
+		}
+		if current, err = resolveLinkTarget(destDir, filepath.Dir(current), link, hops); err != nil {
+			return "", err
+		}
+	}
+
+	return current, nil
+}
+
+// checkNoSymlinkParents makes sure none of the folders between destDir
+// and path are symlinks, as writing through one could escape destDir
+func checkNoSymlinkParents(destDir, path string) error {
+	rel, err := filepath.Rel(destDir, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return err
+	}
+
+	current := destDir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("parent %q is a symlink", current)
+		}
 	}
 
 	return nil
 }
+
+// removeExisting removes a non-directory entry at path, if any
+func removeExisting(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s already exists as a directory", path)
+	}
+	return os.Remove(path)
+}
+
+func writeFile(r io.Reader, path string, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	// Copy content
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// isWithin reports whether path is dir or inside of it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
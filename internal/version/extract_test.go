@@ -0,0 +1,224 @@
+package version
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+	mode     int64
+}
+
+// buildTarGz creates an in-memory gzipped tarball from entries
+func buildTarGz(t *testing.T, entries []tarEntry) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		header := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     mode,
+			Size:     int64(len(e.body)),
+			ModTime:  time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC),
+		}
+		if e.typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatalf("Failed to write tar body: %v", err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestExtractTarGz(t *testing.T) {
+	destDir := filepath.Join(t.TempDir(), "go1.24.2")
+
+	archive := buildTarGz(t, []tarEntry{
+		{name: "go/", typeflag: tar.TypeDir},
+		{name: "go/bin/", typeflag: tar.TypeDir},
+		{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary", mode: 0755},
+		{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.24.2"},
+		{name: "go/misc/VERSION.link", typeflag: tar.TypeSymlink, linkname: "../VERSION"},
+		{name: "go/misc/VERSION.hard", typeflag: tar.TypeLink, linkname: "go/VERSION"},
+	})
+
+	if err := extractTarGz(context.Background(), archive, destDir); err != nil {
+		t.Fatalf("extractTarGz() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(destDir, "bin", "go"))
+	if err != nil {
+		t.Fatalf("Expected bin/go to exist, got error: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("bin/go mode = %v, want %v", info.Mode().Perm(), os.FileMode(0755))
+	}
+
+	wantTime := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	if !info.ModTime().Equal(wantTime) {
+		t.Errorf("bin/go modification time = %v, want %v", info.ModTime(), wantTime)
+	}
+
+	binInfo, err := os.Stat(filepath.Join(destDir, "bin"))
+	if err != nil {
+		t.Fatalf("Expected bin to exist, got error: %v", err)
+	}
+	if !binInfo.ModTime().Equal(wantTime) {
+		t.Errorf("bin modification time = %v, want %v", binInfo.ModTime(), wantTime)
+	}
+
+	target, err := os.Readlink(filepath.Join(destDir, "misc", "VERSION.link"))
+	if err != nil {
+		t.Fatalf("Expected misc/VERSION.link to be a symlink, got error: %v", err)
+	}
+	if target != "../VERSION" {
+		t.Errorf("Symlink target = %v, want ../VERSION", target)
+	}
+
+	content, err := os.ReadFile(filepath.Join(destDir, "misc", "VERSION.hard"))
+	if err != nil {
+		t.Fatalf("Expected misc/VERSION.hard to exist, got error: %v", err)
+	}
+	if string(content) != "go1.24.2" {
+		t.Errorf("Hardlink content = %v, want go1.24.2", string(content))
+	}
+}
+
+func TestExtractTarGzRejectsUnsafeEntries(t *testing.T) {
+	testCases := []struct {
+		name       string
+		entries    []tarEntry
+		wantErrMsg string
+	}{
+		{
+			name: "path traversal",
+			entries: []tarEntry{
+				{name: "go/../../evil", typeflag: tar.TypeReg, body: "evil"},
+			},
+			wantErrMsg: `"go/../../evil"`,
+		},
+		{
+			name: "absolute path",
+			entries: []tarEntry{
+				{name: "/tmp/evil", typeflag: tar.TypeReg, body: "evil"},
+			},
+			wantErrMsg: `"/tmp/evil"`,
+		},
+		{
+			name: "symlink escaping destination",
+			entries: []tarEntry{
+				{name: "go/escape", typeflag: tar.TypeSymlink, linkname: "../../outside"},
+			},
+			wantErrMsg: `rejected symlink "go/escape"`,
+		},
+		{
+			name: "absolute symlink",
+			entries: []tarEntry{
+				{name: "go/escape", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+			},
+			wantErrMsg: `rejected symlink "go/escape"`,
+		},
+		{
+			name: "write through symlink",
+			entries: []tarEntry{
+				{name: "go/dir", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "go/dir/file", typeflag: tar.TypeReg, body: "evil"},
+			},
+			wantErrMsg: `"go/dir/file"`,
+		},
+		{
+			name: "symlink escaping through another symlink",
+			entries: []tarEntry{
+				{name: "go/sub/l", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "go/l2", typeflag: tar.TypeSymlink, linkname: "sub/l/.."},
+				{name: "go/h", typeflag: tar.TypeLink, linkname: "go/l2/victim"},
+			},
+			wantErrMsg: `rejected symlink "go/l2"`,
+		},
+		{
+			name: "symlink through a file that may become a link",
+			entries: []tarEntry{
+				{name: "go/sub/l", typeflag: tar.TypeReg, body: "file"},
+				{name: "go/l2", typeflag: tar.TypeSymlink, linkname: "sub/l/.."},
+			},
+			wantErrMsg: `rejected symlink "go/l2"`,
+		},
+		{
+			name: "hardlink through symlink",
+			entries: []tarEntry{
+				{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.24.2"},
+				{name: "go/dir", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "go/h", typeflag: tar.TypeLink, linkname: "go/dir/VERSION"},
+			},
+			wantErrMsg: `rejected hardlink "go/h"`,
+		},
+		{
+			name: "hardlink escaping destination",
+			entries: []tarEntry{
+				{name: "go/passwd", typeflag: tar.TypeLink, linkname: "../../etc/passwd"},
+			},
+			wantErrMsg: `rejected hardlink "go/passwd"`,
+		},
+		{
+			name: "unsupported entry type",
+			entries: []tarEntry{
+				{name: "go/fifo", typeflag: tar.TypeFifo},
+			},
+			wantErrMsg: `"go/fifo"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			destDir := filepath.Join(root, "versions", "go1.24.2")
+
+			err := extractTarGz(context.Background(), buildTarGz(t, tc.entries), destDir)
+			if err == nil {
+				t.Fatal("Expected error for unsafe archive entry, got nil")
+			}
+
+			if !strings.Contains(err.Error(), tc.wantErrMsg) {
+				t.Errorf("Expected error to contain '%s', got '%s'", tc.wantErrMsg, err.Error())
+			}
+
+			if _, err := os.Lstat(filepath.Join(root, "evil")); err == nil {
+				t.Error("Archive entry was written outside the destination")
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// feedCacheDir is the folder of the cache directory that release
+	// lists and checksums fetched from mirrors are kept in
+	feedCacheDir = "feeds"
+	// defaultFeedTTL is how long a cached release list is used before
+	// asking the mirror whether it changed
+	defaultFeedTTL = time.Hour
+)
+
+// feedCache keeps copies of the release lists and checksums fetched
+// from mirrors, so versions can be resolved without asking a mirror
+// every time, or at all while offline
+type feedCache struct {
+	// dir is where copies are kept, caching is disabled if empty
+	dir string
+	// ttl is how long a copy is used before it is revalidated
+	ttl time.Duration
+	// offline only uses cached copies, never the network
+	offline bool
+	// w receives warnings when a stale copy has to be used
+	w io.Writer
+}
+
+// cachedFeed is a response kept in the feed cache
+type cachedFeed struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+	Body         string    `json:"body"`
+}
+
+// path returns the file the response for url is kept in
+func (c feedCache) path(url string) string {
+	if c.dir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// load returns the cached response for url, or nil if there is none
+func (c feedCache) load(url string) *cachedFeed {
+	path := c.path(url)
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var feed cachedFeed
+	if err := json.Unmarshal(data, &feed); err != nil || feed.URL != url {
+		return nil
+	}
+	return &feed
+}
+
+// store keeps feed in the cache. Failing to do so only costs a request
+// later on, so errors are not reported.
+func (c feedCache) store(feed *cachedFeed) {
+	path := c.path(feed.URL)
+	if path == "" {
+		return
+	}
+
+	data, err := json.Marshal(feed)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	tmpFile, err := os.CreateTemp(c.dir, downloadPrefix+"*")
+	if err != nil {
+		return
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+	}
+}
+
+// fetchMemo keeps the bodies a releaseSource fetched, so one operation
+// fetches each url only once, even with the feed cache disabled
+type fetchMemo struct {
+	mu     sync.Mutex
+	bodies map[string][]byte
+}
+
+// fetch fetches the body at url, or returns what the source fetched
+// from url before
+func (s releaseSource) fetch(url string) ([]byte, error) {
+	if s.memo == nil {
+		return s.fetchCached(url)
+	}
+
+	// Holding the lock while fetching keeps concurrent installs from
+	// fetching the same url at the same time
+	s.memo.mu.Lock()
+	defer s.memo.mu.Unlock()
+
+	if body, ok := s.memo.bodies[url]; ok {
+		return body, nil
+	}
+
+	body, err := s.fetchCached(url)
+	if err != nil {
+		return nil, err
+	}
+	if s.memo.bodies == nil {
+		s.memo.bodies = make(map[string][]byte)
+	}
+	s.memo.bodies[url] = body
+	return body, nil
+}
+
+// fetchCached fetches the body at url. A cached copy younger than the
+// TTL is used as is, and older ones are revalidated with the mirror.
+// When the mirror can not be reached, a stale copy is used with a
+// warning.
+func (s releaseSource) fetchCached(url string) ([]byte, error) {
+	cached := s.cache.load(url)
+
+	if s.cache.offline {
+		if cached == nil {
+			return nil, fmt.Errorf("no cached copy of %s to use offline", url)
+		}
+		return []byte(cached.Body), nil
+	}
+
+	if cached != nil && time.Since(cached.Fetched) < s.cache.ttl {
+		return []byte(cached.Body), nil
+	}
+
+	feed, err := s.request(url, cached)
+	if err != nil {
+		if cached == nil || s.context().Err() != nil {
+			return nil, err
+		}
+
+		w := s.cache.w
+		if w == nil {
+			w = io.Discard
+		}
+		fmt.Fprintf(w, "Warning: %v\nUsing the copy cached %s ago\n", err, formatDuration(time.Since(cached.Fetched)))
+		return []byte(cached.Body), nil
+	}
+
+	s.cache.store(feed)
+	return []byte(feed.Body), nil
+}
+
+// request fetches url from the network. If cached is set, the request
+// is conditional and cached is returned again if it is still current.
+func (s releaseSource) request(url string, cached *cachedFeed) (*cachedFeed, error) {
+	req, err := http.NewRequestWithContext(s.context(), "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "gum/1.0")
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		feed := *cached
+		feed.Fetched = time.Now()
+		return &feed, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request for %s failed with status %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+
+	return &cachedFeed{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		Body:         string(body),
+	}, nil
+}
@@ -0,0 +1,217 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReleaseSourceFetch(t *testing.T) {
+	const url = "https://go.dev/dl/?mode=json&include=all"
+
+	testCases := []struct {
+		name string
+		// age of the cached copy, none if zero
+		age        time.Duration
+		offline    bool
+		status     int
+		httpError  error
+		wantBody   string
+		wantETag   string
+		wantCalls  int
+		wantWarn   bool
+		wantErrMsg string
+	}{
+		{
+			name:      "not cached",
+			status:    http.StatusOK,
+			wantBody:  "new",
+			wantETag:  `"new"`,
+			wantCalls: 1,
+		},
+		{
+			name:      "fresh copy",
+			age:       time.Minute,
+			wantBody:  "cached",
+			wantCalls: 0,
+		},
+		{
+			name:      "stale copy not modified",
+			age:       2 * time.Hour,
+			status:    http.StatusNotModified,
+			wantBody:  "cached",
+			wantETag:  `"cached"`,
+			wantCalls: 1,
+		},
+		{
+			name:      "stale copy modified",
+			age:       2 * time.Hour,
+			status:    http.StatusOK,
+			wantBody:  "new",
+			wantETag:  `"new"`,
+			wantCalls: 1,
+		},
+		{
+			name:      "network error",
+			age:       2 * time.Hour,
+			httpError: errors.New("no route to host"),
+			wantBody:  "cached",
+			wantCalls: 1,
+			wantWarn:  true,
+		},
+		{
+			name:      "server error",
+			age:       2 * time.Hour,
+			status:    http.StatusBadGateway,
+			wantBody:  "cached",
+			wantCalls: 1,
+			wantWarn:  true,
+		},
+		{
+			name:       "network error without copy",
+			httpError:  errors.New("no route to host"),
+			wantCalls:  1,
+			wantErrMsg: "no route to host",
+		},
+		{
+			name:      "offline",
+			age:       48 * time.Hour,
+			offline:   true,
+			wantBody:  "cached",
+			wantCalls: 0,
+		},
+		{
+			name:       "offline without copy",
+			offline:    true,
+			wantCalls:  0,
+			wantErrMsg: "no cached copy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cache := feedCache{dir: t.TempDir(), ttl: time.Hour, offline: tc.offline, w: &buf}
+			if tc.age > 0 {
+				cache.store(&cachedFeed{URL: url, ETag: `"cached"`, Fetched: time.Now().Add(-tc.age), Body: "cached"})
+			}
+
+			calls := 0
+			client := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					calls++
+					if tc.age > 0 && req.Header.Get("If-None-Match") != `"cached"` {
+						t.Errorf("Expected a conditional request, got If-None-Match %q", req.Header.Get("If-None-Match"))
+					}
+					if tc.httpError != nil {
+						return nil, tc.httpError
+					}
+					return &http.Response{
+						StatusCode: tc.status,
+						Status:     http.StatusText(tc.status),
+						Header:     http.Header{"Etag": []string{`"new"`}},
+						Body:       io.NopCloser(strings.NewReader("new")),
+					}, nil
+				},
+			}
+
+			src := releaseSource{client: client, cache: cache}
+			body, err := src.fetch(url)
+
+			if calls != tc.wantCalls {
+				t.Errorf("Expected %d requests, got %d", tc.wantCalls, calls)
+			}
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("releaseSource.fetch() error = %v", err)
+			}
+			if string(body) != tc.wantBody {
+				t.Errorf("releaseSource.fetch() = %q, want %q", body, tc.wantBody)
+			}
+			if warned := strings.Contains(buf.String(), "Warning:"); warned != tc.wantWarn {
+				t.Errorf("Expected warning %v, got output '%s'", tc.wantWarn, buf.String())
+			}
+
+			if tc.wantETag != "" {
+				cached := cache.load(url)
+				if cached == nil || cached.ETag != tc.wantETag || cached.Body != tc.wantBody {
+					t.Errorf("Expected the cache to hold %q with ETag %s, got %+v", tc.wantBody, tc.wantETag, cached)
+				}
+				if time.Since(cached.Fetched) > time.Minute {
+					t.Errorf("Expected the cached copy to be refreshed, fetched %s", cached.Fetched)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionManager_InstallOffline(t *testing.T) {
+	release := newMockRelease(t, "go1.24.2")
+
+	online := true
+	client := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if !online {
+				t.Errorf("Unexpected request for %s while offline", req.URL)
+				return nil, errors.New("offline")
+			}
+			return release.Do(req)
+		},
+	}
+
+	tmpDir := t.TempDir()
+	installDir := filepath.Join(tmpDir, "versions")
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		httpClient: client,
+		installDir: installDir,
+		cacheDir:   filepath.Join(tmpDir, "cache"),
+		feedTTL:    time.Hour,
+	}
+
+	var buf bytes.Buffer
+	if err := manager.Install(context.Background(), "1.24", InstallOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Install() error = %v, output = %s", err, buf.String())
+	}
+
+	online = false
+	manager.offline = true
+	if err := os.RemoveAll(installDir); err != nil {
+		t.Fatalf("Failed to remove install directory: %v", err)
+	}
+
+	buf.Reset()
+	if err := manager.Install(context.Background(), "1.24", InstallOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Install() offline error = %v, output = %s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Resolved 1.24 to go1.24.2") || !strings.Contains(buf.String(), "Using cached") {
+		t.Errorf("Expected the version and archive to come from the cache, got '%s'", buf.String())
+	}
+	if !manager.isComplete(filepath.Join(installDir, "go1.24.2")) {
+		t.Error("Expected go1.24.2 to be installed")
+	}
+
+	// Archives that were never downloaded can not be installed offline
+	if err := manager.pruneCache(0, io.Discard); err != nil {
+		t.Fatalf("VersionManager.pruneCache() error = %v", err)
+	}
+	os.RemoveAll(installDir)
+	err := manager.Install(context.Background(), "1.24.2", InstallOptions{}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "not in the download cache") {
+		t.Errorf("Expected an error about the missing archive, got %v", err)
+	}
+}
@@ -18,6 +18,14 @@ type FileSystem interface {
 	Remove(name string) error
 	EvalSymlinks(path string) (string, error)
 	Open(name string) (io.ReadCloser, error)
+	Rename(oldpath, newpath string) error
+	ReadDir(name string) ([]os.DirEntry, error)
+	Getwd() (string, error)
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	// TryLock takes an advisory lock on the file at name without
+	// waiting, returning a *lockedError while another process holds it.
+	// The returned function releases the lock.
+	TryLock(name string) (func() error, error)
 }
 
 // OSFileSystem implements FileSystem using the os package
@@ -62,3 +70,23 @@ func (fs OSFileSystem) EvalSymlinks(path string) (string, error) {
 func (fs OSFileSystem) Open(name string) (io.ReadCloser, error) {
 	return os.Open(name)
 }
+
+func (fs OSFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (fs OSFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (fs OSFileSystem) Getwd() (string, error) {
+	return os.Getwd()
+}
+
+func (fs OSFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (fs OSFileSystem) TryLock(name string) (func() error, error) {
+	return tryLockFile(name)
+}
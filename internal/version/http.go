@@ -1,7 +1,27 @@
 package version
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultConnectTimeout limits connecting to a server
+	defaultConnectTimeout = 30 * time.Second
+	// defaultIdleTimeout fails requests that stall for this long
+	defaultIdleTimeout = time.Minute
 )
 
 // HTTPClient abstracts HTTP operations for better testability
@@ -9,19 +29,318 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// DefaultHTTPClient implements HTTPClient using the standard http package
+// DefaultHTTPClient implements HTTPClient using the standard http package.
+// Requests are authenticated with credentials from the netrc file, or
+// with a bearer token for the hosts it is meant for.
 type DefaultHTTPClient struct {
-	client *http.Client
+	client     *http.Client
+	netrc      map[string]netrcEntry
+	token      string
+	tokenHosts []string
+	// insecure is set when TLS certificates are not verified, which
+	// is reported to warnings before the first request
+	insecure bool
+	warnings io.Writer
+	warnOnce sync.Once
 }
 
-// NewDefaultHTTPClient creates a new DefaultHTTPClient
-func NewDefaultHTTPClient() *DefaultHTTPClient {
-	return &DefaultHTTPClient{
-		client: http.DefaultClient,
+// netrcEntry holds the credentials of one machine in a netrc file
+type netrcEntry struct {
+	login    string
+	password string
+}
+
+// NewDefaultHTTPClient creates a new DefaultHTTPClient configured by
+// config. The auth token of config is only sent to tokenHosts.
+func NewDefaultHTTPClient(config Config, tokenHosts []string) (*DefaultHTTPClient, error) {
+	proxy, err := proxyFunc(config.Proxy)
+	if err != nil {
+		return nil, err
 	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if config.CABundle != "" {
+		if tlsConfig.RootCAs, err = loadCABundle(config.CABundle); err != nil {
+			return nil, err
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: config.ConnectTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil || config.IdleTimeout <= 0 {
+				return conn, err
+			}
+			return &idleTimeoutConn{Conn: conn, timeout: config.IdleTimeout}, nil
+		},
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.ConnectTimeout,
+		ResponseHeaderTimeout: config.IdleTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &DefaultHTTPClient{
+		client:     &http.Client{Transport: transport, Timeout: config.Timeout},
+		netrc:      readNetrc(netrcPath()),
+		token:      config.AuthToken,
+		tokenHosts: tokenHosts,
+		insecure:   config.InsecureSkipVerify,
+		warnings:   os.Stderr,
+	}, nil
 }
 
 // Do executes an HTTP request
 func (c *DefaultHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if c.insecure {
+		c.warnOnce.Do(func() {
+			fmt.Fprintln(c.warnings, "WARNING: TLS certificate verification is disabled by insecure_skip_verify.")
+			fmt.Fprintln(c.warnings, "WARNING: Anyone on the network can tamper with release lists, checksums and downloads.")
+		})
+	}
+
+	c.authenticate(req)
 	return c.client.Do(req)
 }
+
+// idleTimeoutConn is a connection that fails once nothing could be
+// read from or written to it for timeout, so a stalled transfer ends
+// instead of hanging forever
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
+
+func (c *idleTimeoutConn) Write(p []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(p)
+}
+
+// loadCABundle returns the system certificates along with those in the
+// PEM file at path, for servers behind TLS intercepting proxies
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+
+	return pool, nil
+}
+
+// proxyFunc returns which proxy to send each request through: proxy if
+// set, otherwise the one in HTTPS_PROXY or HTTP_PROXY for the scheme of
+// the request. Hosts listed in NO_PROXY are always reached directly.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	httpsProxy, httpProxy := proxy, proxy
+	if proxy == "" {
+		httpsProxy = getenvAny("HTTPS_PROXY", "https_proxy")
+		httpProxy = getenvAny("HTTP_PROXY", "http_proxy")
+	}
+
+	httpsURL, err := parseProxyURL(httpsProxy)
+	if err != nil {
+		return nil, err
+	}
+	httpURL, err := parseProxyURL(httpProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	noProxy := getenvAny("NO_PROXY", "no_proxy")
+
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL := httpURL
+		if req.URL.Scheme == "https" {
+			proxyURL = httpsURL
+		}
+		if proxyURL == nil || matchesNoProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// parseProxyURL parses a proxy address, which like for curl may leave
+// out the scheme of a plain HTTP proxy
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy address %q", proxy)
+	}
+	return proxyURL, nil
+}
+
+// matchesNoProxy reports whether the host of u is in noProxy, a comma
+// separated list of host names, domains, IP ranges and * for all hosts.
+// Entries may be limited to a port with a :port suffix.
+func matchesNoProxy(u *url.URL, noProxy string) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	for _, entry := range strings.Split(strings.ToLower(noProxy), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = entryHost
+		}
+
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if entry != "" && (host == entry || strings.HasSuffix(host, "."+entry)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// authenticate adds credentials for the host of req, if there are any.
+// The http package drops them again on redirects to other hosts.
+func (c *DefaultHTTPClient) authenticate(req *http.Request) {
+	if req.Header.Get("Authorization") != "" {
+		return
+	}
+
+	host := req.URL.Hostname()
+	if entry, ok := c.netrc[host]; ok {
+		req.SetBasicAuth(entry.login, entry.password)
+		return
+	}
+
+	if c.token != "" && slices.Contains(c.tokenHosts, host) {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// netrcPath returns the location of the netrc file, honouring $NETRC
+// like the go command does
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// readNetrc reads the machine entries of the netrc file at path. Like
+// the go command, it ignores default entries and macros, and a
+// missing or unreadable file simply has no entries.
+func readNetrc(path string) map[string]netrcEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parseNetrc(string(data))
+}
+
+func parseNetrc(data string) map[string]netrcEntry {
+	entries := map[string]netrcEntry{}
+
+	var machine string
+	var entry netrcEntry
+	flush := func() {
+		if machine != "" && entry.login != "" {
+			entries[machine] = entry
+		}
+		machine, entry = "", netrcEntry{}
+	}
+
+	inMacro := false
+	for _, line := range strings.Split(data, "\n") {
+		// Macros run until the next empty line
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "macdef") {
+			flush()
+			inMacro = true
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			if strings.HasPrefix(fields[i], "#") {
+				break
+			}
+
+			switch fields[i] {
+			case "default":
+				flush()
+			case "machine", "login", "password":
+				if i+1 >= len(fields) {
+					continue
+				}
+				value := fields[i+1]
+				switch fields[i] {
+				case "machine":
+					flush()
+					machine = value
+				case "login":
+					entry.login = value
+				case "password":
+					entry.password = value
+				}
+				i++
+			}
+		}
+	}
+	flush()
+
+	return entries
+}
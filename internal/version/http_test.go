@@ -0,0 +1,357 @@
+package version
+
+import (
+	"bytes"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearProxyEnv unsets the proxy environment variables
+func clearProxyEnv(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestParseNetrc(t *testing.T) {
+	data := `# credentials
+machine artifactory.example.com
+  login ci
+  password s3cret
+
+machine other.example.com login me password pw # inline
+macdef init
+machine macro.example.com login nope password nope
+
+default login anonymous password guest
+`
+
+	want := map[string]netrcEntry{
+		"artifactory.example.com": {login: "ci", password: "s3cret"},
+		"other.example.com":       {login: "me", password: "pw"},
+	}
+
+	if got := parseNetrc(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNetrc() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDefaultHTTPClientAuthenticate(t *testing.T) {
+	client := &DefaultHTTPClient{
+		netrc:      map[string]netrcEntry{"netrc.example.com": {login: "ci", password: "s3cret"}},
+		token:      "t0ken",
+		tokenHosts: []string{"mirror.example.com", "netrc.example.com"},
+	}
+
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://mirror.example.com/go/?mode=json", "Bearer t0ken"},
+		{"https://netrc.example.com/go/go1.24.2.linux-amd64.tar.gz", "Basic Y2k6czNjcmV0"},
+		{"https://go.dev/dl/?mode=json", ""},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+
+		client.authenticate(req)
+
+		if got := req.Header.Get("Authorization"); got != tc.expected {
+			t.Errorf("Authorization for %s = %q, want %q", tc.url, got, tc.expected)
+		}
+	}
+}
+
+func TestTokenHosts(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+
+	config := defaultConfig(OSFileSystem{})
+	config.GoProxy = []string{"https://proxy.example.com"}
+	config.AuthToken = "t0ken"
+
+	client, err := NewDefaultHTTPClient(config, tokenHosts(config))
+	if err != nil {
+		t.Fatalf("NewDefaultHTTPClient() error = %v", err)
+	}
+
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://proxy.example.com/golang.org/toolchain/@v/list", "Bearer t0ken"},
+		{"https://proxy.example.com/sumdb/sum.golang.org/latest", "Bearer t0ken"},
+		{"https://go.dev/dl/?mode=json", ""},
+		{"https://sum.golang.org/latest", ""},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+
+		client.authenticate(req)
+
+		if got := req.Header.Get("Authorization"); got != tc.expected {
+			t.Errorf("Authorization for %s = %q, want %q", tc.url, got, tc.expected)
+		}
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	testCases := []struct {
+		name     string
+		proxy    string
+		env      map[string]string
+		url      string
+		expected string
+	}{
+		{
+			name:     "no proxy",
+			url:      "https://go.dev/dl/",
+			expected: "",
+		},
+		{
+			name:     "HTTPS_PROXY",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.example.com:3128", "HTTP_PROXY": "http://other.example.com"},
+			url:      "https://go.dev/dl/",
+			expected: "http://proxy.example.com:3128",
+		},
+		{
+			name:     "HTTP_PROXY for plain HTTP",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.example.com:3128", "http_proxy": "other.example.com"},
+			url:      "http://mirror.internal/go/",
+			expected: "http://other.example.com",
+		},
+		{
+			name:     "NO_PROXY",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.example.com:3128", "NO_PROXY": "localhost,.internal"},
+			url:      "https://mirror.internal/go/",
+			expected: "",
+		},
+		{
+			name:     "configured proxy takes precedence",
+			proxy:    "corp-proxy:8080",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.example.com:3128"},
+			url:      "https://go.dev/dl/",
+			expected: "http://corp-proxy:8080",
+		},
+		{
+			name:     "configured proxy honours NO_PROXY",
+			proxy:    "corp-proxy:8080",
+			env:      map[string]string{"no_proxy": "go.dev"},
+			url:      "https://go.dev/dl/",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clearProxyEnv(t)
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			proxy, err := proxyFunc(tc.proxy)
+			if err != nil {
+				t.Fatalf("proxyFunc() error = %v", err)
+			}
+
+			req, err := http.NewRequest("GET", tc.url, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+
+			got, err := proxy(req)
+			if err != nil {
+				t.Fatalf("proxy(%s) error = %v", tc.url, err)
+			}
+			gotURL := ""
+			if got != nil {
+				gotURL = got.String()
+			}
+			if gotURL != tc.expected {
+				t.Errorf("proxy(%s) = %q, want %q", tc.url, gotURL, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesNoProxy(t *testing.T) {
+	noProxy := "localhost, .corp.example.com,*.internal,10.0.0.0/8,mirror.example.com:8443"
+
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{"http://localhost:8080/", true},
+		{"https://corp.example.com/", true},
+		{"https://artifactory.corp.example.com/", true},
+		{"https://mirror.internal/", true},
+		{"https://10.1.2.3/", true},
+		{"https://11.1.2.3/", false},
+		{"https://mirror.example.com:8443/", true},
+		{"https://mirror.example.com/", false},
+		{"https://go.dev/", false},
+		{"https://notcorp.example.com/", false},
+	}
+
+	for _, tc := range testCases {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tc.url, err)
+		}
+		if got := matchesNoProxy(u, noProxy); got != tc.expected {
+			t.Errorf("matchesNoProxy(%s) = %v, want %v", tc.url, got, tc.expected)
+		}
+	}
+
+	if u, _ := url.Parse("https://go.dev/"); !matchesNoProxy(u, "*") {
+		t.Error("Expected * to match every host")
+	}
+}
+
+func TestDefaultHTTPClientTLS(t *testing.T) {
+	clearProxyEnv(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// The test server's certificate stands in for a corporate CA
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, certPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		config     Config
+		wantErrMsg string
+		wantWarn   bool
+	}{
+		{
+			name:       "untrusted certificate",
+			config:     Config{},
+			wantErrMsg: "certificate",
+		},
+		{
+			name:   "CA bundle",
+			config: Config{CABundle: caBundle},
+		},
+		{
+			name:     "insecure skip verify",
+			config:   Config{InsecureSkipVerify: true},
+			wantWarn: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewDefaultHTTPClient(tc.config, nil)
+			if err != nil {
+				t.Fatalf("NewDefaultHTTPClient() error = %v", err)
+			}
+			var warnings bytes.Buffer
+			client.warnings = &warnings
+
+			for range 2 {
+				req, _ := http.NewRequest("GET", server.URL, nil)
+				resp, err := client.Do(req)
+
+				if tc.wantErrMsg != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+						t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+					}
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("DefaultHTTPClient.Do() error = %v", err)
+				}
+				resp.Body.Close()
+			}
+
+			if got := strings.Count(warnings.String(), "TLS certificate verification is disabled"); got != map[bool]int{true: 1}[tc.wantWarn] {
+				t.Errorf("Expected warning %v once, got '%s'", tc.wantWarn, warnings.String())
+			}
+		})
+	}
+}
+
+func TestNewDefaultHTTPClientInvalidCABundle(t *testing.T) {
+	clearProxyEnv(t)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caBundle, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	for path, wantErrMsg := range map[string]string{
+		caBundle:                               "no PEM certificates",
+		filepath.Join(t.TempDir(), "gone.pem"): "failed to read CA bundle",
+	} {
+		if _, err := NewDefaultHTTPClient(Config{CABundle: path}, nil); err == nil || !strings.Contains(err.Error(), wantErrMsg) {
+			t.Errorf("Expected error to contain '%s', got '%v'", wantErrMsg, err)
+		}
+	}
+}
+
+func TestDefaultHTTPClientIdleTimeout(t *testing.T) {
+	clearProxyEnv(t)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		io.WriteString(w, "half")
+		w.(http.Flusher).Flush()
+		// Stall in the middle of the body
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewDefaultHTTPClient(Config{IdleTimeout: 100 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatalf("NewDefaultHTTPClient() error = %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("DefaultHTTPClient.Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(resp.Body)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "timeout") {
+			t.Errorf("Expected a timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the stalled download to time out")
+	}
+}
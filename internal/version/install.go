@@ -0,0 +1,119 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// installResult is the outcome of installing one of several versions
+type installResult struct {
+	requested string
+	version   string
+	err       error
+}
+
+// InstallAll installs several Go versions at once. The release list is
+// fetched once to resolve all of them, after which opts.Parallel
+// versions are downloaded and installed at the same time. A summary
+// shows which versions were installed, and an error is returned if any
+// of them failed.
+func (m *VersionManager) InstallAll(ctx context.Context, versions []string, opts InstallOptions, w io.Writer) error {
+	if len(versions) == 0 {
+		return fmt.Errorf("no version provided")
+	}
+	if len(versions) == 1 {
+		return m.Install(ctx, versions[0], opts, w)
+	}
+
+	src := m.source(ctx, w)
+
+	var results []*installResult
+	for _, v := range versions {
+		resolved, err := m.resolveInstall(ctx, v, opts, src, w)
+		if err != nil {
+			results = append(results, &installResult{requested: v, err: err})
+			continue
+		}
+
+		// 1.24 and 1.24.2 may well be the same release
+		if slices.ContainsFunc(results, func(r *installResult) bool { return r.version == resolved }) {
+			continue
+		}
+		results = append(results, &installResult{requested: v, version: resolved})
+	}
+
+	var labels []string
+	for _, result := range results {
+		labels = append(labels, result.label())
+	}
+	board := newProgressBoard(w, labels)
+
+	parallel := max(opts.Parallel, 1)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	for i, result := range results {
+		if result.err != nil {
+			board.setStatus(i, "Failed")
+			continue
+		}
+
+		board.setStatus(i, "Queued")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Warnings about cached release lists belong to this version
+			out := board.writer(i)
+			versionSrc := src
+			versionSrc.cache.w = out
+
+			board.setStatus(i, "Installing")
+			result.err = m.install(ctx, versionSrc, result.version, out)
+			if result.err != nil {
+				board.setStatus(i, "Failed")
+			} else {
+				board.setStatus(i, "Installed")
+			}
+		}()
+	}
+	wg.Wait()
+
+	return writeInstallSummary(results, m.installDir, w)
+}
+
+// label names the version of the result for output
+func (r *installResult) label() string {
+	if r.version != "" {
+		return r.version
+	}
+	return r.requested
+}
+
+// writeInstallSummary prints a table of results, and returns an error
+// if any version failed
+func writeInstallSummary(results []*installResult, installDir string, w io.Writer) error {
+	failed := 0
+
+	fmt.Fprintf(w, "\n%-12s %-6s  %s\n", "VERSION", "RESULT", "DETAIL")
+	for _, result := range results {
+		status, detail := "ok", filepath.Join(installDir, result.version)
+		if result.err != nil {
+			status, detail = "FAIL", result.err.Error()
+			failed++
+		}
+		fmt.Fprintf(w, "%-12s %-6s  %s\n", result.label(), status, detail)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed with %d of %d Go versions", failed, len(results))
+	}
+
+	fmt.Fprintf(w, "\nInstalled all %d Go versions\n", len(results))
+	return nil
+}
@@ -0,0 +1,134 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVersionManager_InstallAll(t *testing.T) {
+	release := newMockRelease(t, "go1.22.12", "go1.23.8", "go1.24.2")
+
+	var mu sync.Mutex
+	feedRequests, inFlight, maxInFlight := 0, 0, 0
+	client := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.String(), "mode=json") {
+				mu.Lock()
+				feedRequests++
+				mu.Unlock()
+				return release.Do(req)
+			}
+
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+
+			// Give the other downloads a chance to start
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return release.Do(req)
+		},
+	}
+
+	installDir := filepath.Join(t.TempDir(), "versions")
+	manager := &VersionManager{
+		fs:          OSFileSystem{},
+		httpClient:  client,
+		installDir:  installDir,
+		lockTimeout: time.Minute,
+	}
+
+	var buf bytes.Buffer
+	err := manager.InstallAll(context.Background(), []string{"1.22", "1.23.8", "go1.23.8", "1.24.2", "1.99"}, InstallOptions{Parallel: 3}, &buf)
+	output := buf.String()
+
+	if err == nil || !strings.Contains(err.Error(), "failed with 1 of 4 Go versions") {
+		t.Errorf("Expected one of four versions to fail, got %v", err)
+	}
+	if feedRequests != 1 {
+		t.Errorf("Expected the release list to be fetched once, got %d requests", feedRequests)
+	}
+	if maxInFlight < 2 {
+		t.Errorf("Expected downloads to run at the same time, got at most %d at once", maxInFlight)
+	}
+
+	for _, v := range []string{"go1.22.12", "go1.23.8", "go1.24.2"} {
+		if !manager.isComplete(filepath.Join(installDir, v)) {
+			t.Errorf("Expected %s to be installed", v)
+		}
+		if !strings.Contains(output, "["+v+"] Successfully installed") {
+			t.Errorf("Expected the output of %s to be marked with its version, got '%s'", v, output)
+		}
+	}
+
+	for _, want := range []string{
+		"go1.22.12    ok      " + filepath.Join(installDir, "go1.22.12"),
+		"1.99         FAIL    failed to resolve version 1.99",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected summary to contain '%s', got '%s'", want, output)
+		}
+	}
+	if strings.Contains(output, "Downloading...") {
+		t.Errorf("Expected no progress updates when not writing to a terminal, got '%s'", output)
+	}
+}
+
+func TestProgressBoard(t *testing.T) {
+	testCases := []struct {
+		name       string
+		live       bool
+		wantOutput []string
+		dontWant   []string
+	}{
+		{
+			name:       "not a terminal",
+			live:       false,
+			wantOutput: []string{"[go1.24.2] Downloading Go go1.24.2\n", "[go1.24.2] Verified checksum\n"},
+			dontWant:   []string{"50.0%", "\x1b["},
+		},
+		{
+			name:       "terminal",
+			live:       true,
+			wantOutput: []string{"[go1.24.2] Downloading Go go1.24.2\n", "go1.24.2  Downloading... 50.0%\n", "go1.23.8  Queued\n", "\x1b[2A\x1b[J"},
+			dontWant:   []string{"[go1.24.2] Downloading... 50.0%"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			board := newProgressBoard(&buf, []string{"go1.24.2", "go1.23.8"})
+			board.live = tc.live
+
+			board.setStatus(1, "Queued")
+			w := board.writer(0)
+			for _, chunk := range []string{"Downloading Go go1.24.2\n", "\rDownloading... 5", "0.0%", "\n", "Verified checksum\n"} {
+				if _, err := w.Write([]byte(chunk)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			for _, want := range tc.wantOutput {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected output to contain %q, got %q", want, buf.String())
+				}
+			}
+			for _, unwanted := range tc.dontWant {
+				if strings.Contains(buf.String(), unwanted) {
+					t.Errorf("Expected output not to contain %q, got %q", unwanted, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// lockPrefix names the lock files in the install directory. Each
+	// version has a lock of its own, so different versions can be
+	// installed at the same time.
+	lockPrefix = ".lock-"
+	// activeLock is the lock guarding the links in the bin directory
+	activeLock = "active"
+	// defaultLockTimeout is how long to wait for another gum working on
+	// the same version
+	defaultLockTimeout = 10 * time.Minute
+	// lockPollInterval is how often a lock held elsewhere is tried again
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// lockedError is returned by FileSystem.TryLock when another process
+// holds the lock
+type lockedError struct {
+	path string
+	// pid is the process holding the lock, 0 if unknown
+	pid int
+}
+
+func (e *lockedError) Error() string {
+	return fmt.Sprintf("%s is held by %s", e.path, e.holder())
+}
+
+// holder describes the process holding the lock
+func (e *lockedError) holder() string {
+	if e.pid == 0 {
+		return "another gum"
+	}
+	return fmt.Sprintf("another gum (process %d)", e.pid)
+}
+
+// lockPath returns the lock file for name
+func (m *VersionManager) lockPath(name string) string {
+	return filepath.Join(m.installDir, lockPrefix+name)
+}
+
+// lock takes the lock called name, which is a version or activeLock.
+// While another gum holds it, lock waits for up to the lock timeout.
+// The returned function releases the lock again.
+func (m *VersionManager) lock(ctx context.Context, name string, w io.Writer) (func(), error) {
+	if err := m.fs.MkdirAll(m.installDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create installation directory: %w", err)
+	}
+
+	what := "Go " + name
+	if name == activeLock {
+		what = "the active version"
+	}
+
+	deadline := time.Now().Add(m.lockTimeout)
+	waiting := false
+	for {
+		unlock, err := m.fs.TryLock(m.lockPath(name))
+		if err == nil {
+			return func() { unlock() }, nil
+		}
+
+		var locked *lockedError
+		if !errors.As(err, &locked) {
+			return nil, fmt.Errorf("failed to lock %s: %w", what, err)
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for %s to finish with %s. Try again later, or raise lock_timeout", m.lockTimeout, locked.holder(), what)
+		}
+
+		if !waiting {
+			fmt.Fprintf(w, "Waiting for %s to finish with %s...\n", locked.holder(), what)
+			waiting = true
+		}
+
+		if err := sleepContext(ctx, min(lockPollInterval, time.Until(deadline))); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryLock takes the lock on version v without waiting, and returns the
+// function releasing it again. It returns nil if another process holds
+// the lock, or it can not be taken at all.
+func (m *VersionManager) tryLock(v string) func() {
+	unlock, err := m.fs.TryLock(m.lockPath(v))
+	if err != nil {
+		return nil
+	}
+	return func() { unlock() }
+}
+
+// writeLockOwner records the current process in a lock file, so others
+// waiting for the lock can tell who holds it
+func writeLockOwner(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+// readLockOwner returns the process recorded in a lock file, 0 if none
+func readLockOwner(r io.Reader) int {
+	data, err := io.ReadAll(io.LimitReader(r, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,68 @@
+//go:build !unix
+
+package version
+
+import (
+	"os"
+	"time"
+)
+
+// newLockGrace is how long a lock file that does not name its owner yet
+// is assumed to be in the middle of being taken
+const newLockGrace = 10 * time.Second
+
+// tryLockFile takes the lock on path by creating the file, which fails
+// while another process holds it. A lock file whose owner is no longer
+// running is stale and gets removed.
+func tryLockFile(path string) (func() error, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			if err := writeLockOwner(file); err != nil {
+				file.Close()
+				os.Remove(path)
+				return nil, err
+			}
+			return func() error {
+				file.Close()
+				return os.Remove(path)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		pid, stale := lockOwner(path)
+		if !stale {
+			return nil, &lockedError{path: path, pid: pid}
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+// lockOwner returns the process holding the lock file at path, and
+// whether the lock is stale because that process is no longer running
+func lockOwner(path string) (int, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	pid := readLockOwner(file)
+	if pid == 0 {
+		info, err := file.Stat()
+		return 0, err == nil && time.Since(info.ModTime()) > newLockGrace
+	}
+
+	// Finding a process fails once it has exited
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, true
+	}
+	process.Release()
+	return pid, false
+}
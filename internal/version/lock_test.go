@@ -0,0 +1,247 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVersionManager_Lock(t *testing.T) {
+	manager := &VersionManager{
+		fs:          OSFileSystem{},
+		installDir:  filepath.Join(t.TempDir(), "versions"),
+		lockTimeout: 300 * time.Millisecond,
+	}
+	ctx := context.Background()
+
+	unlock, err := manager.lock(ctx, "go1.24.2", io.Discard)
+	if err != nil {
+		t.Fatalf("VersionManager.lock() error = %v", err)
+	}
+
+	// Other versions can be locked at the same time
+	unlockOther, err := manager.lock(ctx, "go1.23.8", io.Discard)
+	if err != nil {
+		t.Fatalf("VersionManager.lock() of another version error = %v", err)
+	}
+	unlockOther()
+
+	var buf bytes.Buffer
+	start := time.Now()
+	_, err = manager.lock(ctx, "go1.24.2", &buf)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected the lock to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < manager.lockTimeout {
+		t.Errorf("Expected to wait for the lock timeout, gave up after %s", elapsed)
+	}
+	holder := fmt.Sprintf("process %d", os.Getpid())
+	if !strings.Contains(buf.String(), "Waiting for another gum ("+holder+")") || !strings.Contains(err.Error(), holder) {
+		t.Errorf("Expected the holder of the lock to be named, got output '%s', error '%v'", buf.String(), err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := manager.lock(cancelled, "go1.24.2", io.Discard); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected waiting for the lock to be cancelled, got %v", err)
+	}
+
+	if unlockAgain := manager.tryLock("go1.24.2"); unlockAgain != nil {
+		unlockAgain()
+		t.Error("Expected go1.24.2 to be locked")
+	}
+
+	unlock()
+	unlockAgain := manager.tryLock("go1.24.2")
+	if unlockAgain == nil {
+		t.Fatal("Expected go1.24.2 to be unlocked")
+	}
+	unlockAgain()
+	unlock, err = manager.lock(ctx, "go1.24.2", io.Discard)
+	if err != nil {
+		t.Fatalf("VersionManager.lock() after unlocking error = %v", err)
+	}
+	unlock()
+}
+
+func TestVersionManager_LockStale(t *testing.T) {
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		installDir: t.TempDir(),
+	}
+
+	// A gum that died while holding the lock left its lock file behind
+	deadPID := 1 << 22
+	if err := os.WriteFile(manager.lockPath("go1.24.2"), []byte(fmt.Sprintf("%d\n", deadPID)), 0644); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	unlock, err := manager.lock(context.Background(), "go1.24.2", io.Discard)
+	if err != nil {
+		t.Fatalf("Expected the stale lock to be taken over, got %v", err)
+	}
+	unlock()
+}
+
+func TestVersionManager_InstallConcurrent(t *testing.T) {
+	release := newMockRelease(t, "go1.24.2")
+
+	var mu sync.Mutex
+	downloads := 0
+	client := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if !strings.Contains(req.URL.String(), "mode=json") {
+				mu.Lock()
+				downloads++
+				mu.Unlock()
+			}
+			return release.Do(req)
+		},
+	}
+
+	installDir := filepath.Join(t.TempDir(), "versions")
+	manager := &VersionManager{
+		fs:          OSFileSystem{},
+		httpClient:  client,
+		installDir:  installDir,
+		lockTimeout: time.Minute,
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = manager.Install(context.Background(), "1.24.2", InstallOptions{}, io.Discard)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("VersionManager.Install() error = %v", err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("Expected the archive to be downloaded once, got %d downloads", downloads)
+	}
+	if !manager.isComplete(filepath.Join(installDir, "go1.24.2")) {
+		t.Error("Expected go1.24.2 to be installed")
+	}
+}
+
+func TestVersionManager_CleanIncompleteInstallsLocked(t *testing.T) {
+	installDir := t.TempDir()
+	writeTestFiles(t, installDir, map[string]string{
+		stagingPrefix + "go1.24.2/VERSION": "go1.24.2",
+		stagingPrefix + "go1.23.8/VERSION": "go1.23.8",
+	})
+
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		installDir: installDir,
+	}
+
+	// go1.24.2 is being installed by another gum
+	unlock, err := manager.lock(context.Background(), "go1.24.2", io.Discard)
+	if err != nil {
+		t.Fatalf("VersionManager.lock() error = %v", err)
+	}
+	defer unlock()
+
+	if err := manager.cleanIncompleteInstalls(io.Discard); err != nil {
+		t.Fatalf("VersionManager.cleanIncompleteInstalls() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(installDir, stagingPrefix+"go1.24.2")); err != nil {
+		t.Errorf("Expected the install in progress to be left alone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(installDir, stagingPrefix+"go1.23.8")); !os.IsNotExist(err) {
+		t.Errorf("Expected the interrupted install to be removed, got %v", err)
+	}
+}
+
+func TestVersionManager_ListHidesInstallInProgress(t *testing.T) {
+	installDir := t.TempDir()
+	writeTestFiles(t, installDir, map[string]string{
+		"go1.23.8/bin/go":                  "",
+		stagingPrefix + "go1.24.2/VERSION": "go1.24.2",
+	})
+
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		installDir: installDir,
+		binDir:     filepath.Join(t.TempDir(), "bin"),
+	}
+
+	// go1.24.2 is being installed by another gum
+	unlock, err := manager.lock(context.Background(), "go1.24.2", io.Discard)
+	if err != nil {
+		t.Fatalf("VersionManager.lock() error = %v", err)
+	}
+	defer unlock()
+
+	var buf bytes.Buffer
+	if err := manager.List(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.List() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "go1.23.8") {
+		t.Errorf("Expected output to contain 'go1.23.8', got '%s'", buf.String())
+	}
+	if strings.Contains(buf.String(), "go1.24.2") {
+		t.Errorf("Expected the install in progress to be hidden, got '%s'", buf.String())
+	}
+}
+
+// removeHookFileSystem is an OSFileSystem calling onRemove before
+// removing anything
+type removeHookFileSystem struct {
+	OSFileSystem
+	onRemove func(path string)
+}
+
+func (fs removeHookFileSystem) RemoveAll(path string) error {
+	fs.onRemove(path)
+	return fs.OSFileSystem.RemoveAll(path)
+}
+
+func TestVersionManager_CleanIncompleteInstallsHoldsLock(t *testing.T) {
+	installDir := t.TempDir()
+	writeTestFiles(t, installDir, map[string]string{
+		stagingPrefix + "go1.23.8/VERSION": "go1.23.8",
+	})
+
+	manager := &VersionManager{installDir: installDir}
+	removed := false
+	manager.fs = removeHookFileSystem{onRemove: func(path string) {
+		removed = true
+		// An install starting now has to wait for the removal
+		if unlock := manager.tryLock("go1.23.8"); unlock != nil {
+			unlock()
+			t.Errorf("Expected go1.23.8 to be locked while removing %s", path)
+		}
+	}}
+
+	if err := manager.cleanIncompleteInstalls(io.Discard); err != nil {
+		t.Fatalf("VersionManager.cleanIncompleteInstalls() error = %v", err)
+	}
+	if !removed {
+		t.Error("Expected the interrupted install to be removed")
+	}
+	if unlock := manager.tryLock("go1.23.8"); unlock == nil {
+		t.Error("Expected go1.23.8 to be unlocked again")
+	} else {
+		unlock()
+	}
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+package version
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile takes an flock on the file at path without waiting. The
+// kernel releases the lock when its holder exits, so a lock file left
+// behind by a process that died is simply taken over.
+func tryLockFile(path string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		pid := readLockOwner(file)
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, &lockedError{path: path, pid: pid}
+		}
+		return nil, err
+	}
+
+	if err := writeLockOwner(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// The file is kept, since removing it would let another process lock
+	// a new file while a third still waits on the old one
+	return func() error {
+		file.Truncate(0)
+		return file.Close()
+	}, nil
+}
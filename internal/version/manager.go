@@ -1,11 +1,67 @@
 package version
 
-import "io"
+import (
+	"context"
+	"io"
+	"time"
+)
 
-// Manager defines the interface for version management operations
+// Manager defines the interface for version management operations.
+// Every operation stops early when its context is cancelled, leaving
+// no partial downloads or versions behind.
 type Manager interface {
-	Install(version string, w io.Writer) error
-	Uninstall(version string, w io.Writer) error
-	Use(version string, w io.Writer) error
-	List(w io.Writer) error
+	Install(ctx context.Context, version string, opts InstallOptions, w io.Writer) error
+	InstallAll(ctx context.Context, versions []string, opts InstallOptions, w io.Writer) error
+	Uninstall(ctx context.Context, version string, w io.Writer) error
+	Use(ctx context.Context, version string, opts UseOptions, w io.Writer) error
+	List(ctx context.Context, w io.Writer) error
+	ListRemote(ctx context.Context, filter string, w io.Writer) error
+	Pin(ctx context.Context, version string, w io.Writer) error
+	Env(ctx context.Context, version, shell string, w io.Writer) error
+	EnableShims(ctx context.Context, w io.Writer) error
+	DisableShims(ctx context.Context, w io.Writer) error
+	ShimExec(ctx context.Context, tool string, args []string, stderr io.Writer) error
+	Exec(ctx context.Context, version string, command []string, opts ExecOptions, stdout, stderr io.Writer) error
+	Matrix(ctx context.Context, versions, command []string, opts MatrixOptions, w io.Writer) error
+	ConfigGet(ctx context.Context, key string, w io.Writer) error
+	ConfigSet(ctx context.Context, key, value string, w io.Writer) error
+	ConfigList(ctx context.Context, w io.Writer) error
+	CacheList(ctx context.Context, w io.Writer) error
+	CacheSize(ctx context.Context, w io.Writer) error
+	CacheClean(ctx context.Context, w io.Writer) error
+	CachePrune(ctx context.Context, olderThan time.Duration, w io.Writer) error
+}
+
+// InstallOptions controls how Install resolves the requested version
+type InstallOptions struct {
+	// Prerelease allows a major.minor version to resolve to a beta or release candidate
+	Prerelease bool
+	// Parallel is how many versions InstallAll installs at once, one if
+	// not set
+	Parallel int
+}
+
+// UseOptions controls how Use activates the requested version
+type UseOptions struct {
+	// Install installs the requested version first if it is missing
+	Install bool
+}
+
+// ExecOptions controls how Exec finds the version to run a command with
+type ExecOptions struct {
+	// Install installs the requested version first if it is missing
+	Install bool
+}
+
+// MatrixOptions controls which versions Matrix runs a command with, and how
+type MatrixOptions struct {
+	// Supported adds the Go releases currently supported upstream
+	Supported bool
+	// Install installs requested versions first if they are missing
+	Install bool
+	// Parallel is how many versions run at once, one if not set
+	Parallel int
+	// LogDir is where the output of each run is written, a new
+	// temporary directory if not set
+	LogDir string
 }
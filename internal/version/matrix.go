@@ -0,0 +1,166 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// matrixResult is the outcome of running the command with one version
+type matrixResult struct {
+	version  string
+	err      error
+	duration time.Duration
+	logPath  string
+	ran      bool
+}
+
+// Matrix runs command once with each of versions, like Exec, and
+// prints a summary of which versions passed. The output of every run
+// goes to a log file of its own rather than to w.
+func (m *VersionManager) Matrix(ctx context.Context, versions, command []string, opts MatrixOptions, w io.Writer) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no command provided")
+	}
+
+	if opts.Supported {
+		supported, err := findSupportedVersions(m.source(ctx, w))
+		if err != nil {
+			return fmt.Errorf("failed to find supported versions: %w", err)
+		}
+		fmt.Fprintf(w, "Supported Go releases: %s\n", strings.Join(supported, ", "))
+		versions = append(versions, supported...)
+	}
+
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions provided")
+	}
+
+	logDir := opts.LogDir
+	if logDir == "" {
+		var err error
+		if logDir, err = os.MkdirTemp("", "gum-matrix-"); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+	} else if err := m.fs.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	// Versions are resolved and installed one at a time, since
+	// installing reports progress to w
+	var results []*matrixResult
+	for _, v := range versions {
+		resolved, err := m.execVersion(ctx, v, opts.Install, w)
+		if err != nil {
+			results = append(results, &matrixResult{version: requestedLabel(v), err: err})
+			continue
+		}
+
+		// 1.24 and 1.24.2 may well be the same release
+		if slices.ContainsFunc(results, func(r *matrixResult) bool { return r.version == resolved }) {
+			continue
+		}
+
+		results = append(results, &matrixResult{
+			version: resolved,
+			logPath: filepath.Join(logDir, resolved+".log"),
+			ran:     true,
+		})
+	}
+
+	parallel := max(opts.Parallel, 1)
+	fmt.Fprintf(w, "Running %s with %d Go versions, logging to %s\n", strings.Join(command, " "), len(results), logDir)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	for _, result := range results {
+		if !result.ran {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := time.Now()
+			result.err = m.runLogged(ctx, result.version, command, result.logPath)
+			result.duration = time.Since(start)
+		}()
+	}
+	wg.Wait()
+
+	return writeMatrixSummary(results, w)
+}
+
+// requestedLabel names version v as it was asked for, for versions
+// that could not be resolved
+func requestedLabel(v string) string {
+	switch {
+	case strings.TrimSpace(v) == "":
+		return strconv.Quote(v)
+	case isVersionConstraint(v) || isReleaseAlias(v) || v == aliasGoMod || v == aliasInstalledLatest:
+		return v
+	}
+	return normaliseVersion(v)
+}
+
+// runLogged runs command with version v, writing its output to logPath
+func (m *VersionManager) runLogged(ctx context.Context, v string, command []string, logPath string) error {
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := m.toolchainCommand(ctx, v, command)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(logFile, "gum: %s failed under Go %s: %v\n", command[0], v, err)
+		return err
+	}
+	return nil
+}
+
+// writeMatrixSummary prints a table of results, and returns an error
+// if any version failed
+func writeMatrixSummary(results []*matrixResult, w io.Writer) error {
+	failed := 0
+
+	fmt.Fprintf(w, "\n%-12s %-6s %8s  %s\n", "VERSION", "RESULT", "TIME", "LOG")
+	for _, result := range results {
+		status, duration, detail := "pass", "", result.logPath
+		if result.ran {
+			duration = fmt.Sprintf("%.1fs", result.duration.Seconds())
+		}
+
+		switch {
+		case result.err != nil && result.ran:
+			status = "FAIL"
+		case result.err != nil:
+			status, detail = "error", result.err.Error()
+		}
+
+		if result.err != nil {
+			failed++
+		}
+		fmt.Fprintf(w, "%-12s %-6s %8s  %s\n", result.version, status, duration, detail)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed with %d of %d Go versions", failed, len(results))
+	}
+
+	fmt.Fprintf(w, "\nPassed with all %d Go versions\n", len(results))
+	return nil
+}
@@ -0,0 +1,173 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVersionManager_Matrix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test tools are shell scripts")
+	}
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"versions/go1.22.4/bin/go": "#!/bin/sh\necho \"testing with $GOROOT\"\n",
+		"versions/go1.23.1/bin/go": "#!/bin/sh\necho \"broken\"\nexit 1\n",
+		"versions/go1.24.2/bin/go": "#!/bin/sh\necho \"testing with $GOROOT\"\n",
+	})
+	installDir := filepath.Join(root, "versions")
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			jsonContent := `[
+				{"version": "go1.25rc1", "stable": false, "files": []},
+				{"version": "go1.24.2", "stable": true, "files": []},
+				{"version": "go1.23.1", "stable": true, "files": []},
+				{"version": "go1.22.4", "stable": true, "files": []}
+			]`
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(jsonContent)),
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		versions     []string
+		opts         MatrixOptions
+		wantErr      bool
+		wantOutput   []string
+		unwantOutput []string
+	}{
+		{
+			name:     "all pass",
+			versions: []string{"1.22", "1.24.2"},
+			opts:     MatrixOptions{Parallel: 2},
+			wantOutput: []string{
+				"go1.22.4     pass",
+				"go1.24.2     pass",
+				"Passed with all 2 Go versions",
+			},
+		},
+		{
+			name:     "failure",
+			versions: []string{"1.22.4", "1.23"},
+			wantErr:  true,
+			wantOutput: []string{
+				"go1.22.4     pass",
+				"go1.23.1     FAIL",
+			},
+		},
+		{
+			name:     "version not installed",
+			versions: []string{"1.22.4", "1.21.0"},
+			wantErr:  true,
+			wantOutput: []string{
+				"go1.21.0     error",
+				"Go go1.21.0 is not installed",
+			},
+		},
+		{
+			name:     "empty version",
+			versions: []string{"1.22.4", ""},
+			wantErr:  true,
+			wantOutput: []string{
+				"go1.22.4     pass",
+				`""           error            no version provided`,
+			},
+		},
+		{
+			name:    "supported versions",
+			opts:    MatrixOptions{Supported: true},
+			wantErr: true,
+			wantOutput: []string{
+				"Supported Go releases: 1.24, 1.23",
+				"go1.24.2     pass",
+				"go1.23.1     FAIL",
+			},
+			unwantOutput: []string{"go1.22.4", "go1.25rc1"},
+		},
+		{
+			name:     "duplicate versions run once",
+			versions: []string{"1.24", "go1.24.2"},
+			wantOutput: []string{
+				"with 1 Go versions",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &VersionManager{
+				fs:         OSFileSystem{},
+				httpClient: mockHTTP,
+				installDir: installDir,
+			}
+
+			logDir := filepath.Join(t.TempDir(), "logs")
+			tc.opts.LogDir = logDir
+
+			var buf bytes.Buffer
+			err := manager.Matrix(context.Background(), tc.versions, []string{"go", "test"}, tc.opts, &buf)
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("VersionManager.Matrix() error = %v, wantErr %v\n%s", err, tc.wantErr, buf.String())
+			}
+
+			for _, want := range tc.wantOutput {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected output to contain '%s', got '%s'", want, buf.String())
+				}
+			}
+
+			for _, unwant := range tc.unwantOutput {
+				if strings.Contains(buf.String(), unwant) {
+					t.Errorf("Expected output not to contain '%s', got '%s'", unwant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestVersionManager_MatrixLogs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test tools are shell scripts")
+	}
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"versions/go1.23.1/bin/go": "#!/bin/sh\necho \"broken\"\nexit 1\n",
+	})
+
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		installDir: filepath.Join(root, "versions"),
+	}
+
+	logDir := filepath.Join(root, "logs")
+	var buf bytes.Buffer
+	if err := manager.Matrix(context.Background(), []string{"1.23.1"}, []string{"go", "test"}, MatrixOptions{LogDir: logDir}, &buf); err == nil {
+		t.Fatal("Expected error for failing version, got nil")
+	}
+
+	content, err := os.ReadFile(filepath.Join(logDir, "go1.23.1.log"))
+	if err != nil {
+		t.Fatalf("Expected log file to exist, got error: %v", err)
+	}
+
+	for _, want := range []string{"broken\n", "gum: go failed under Go go1.23.1: exit status 1"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected log to contain '%s', got '%s'", want, string(content))
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// toolchainModule is the module Go releases are published as on
+	// module proxies, which is where GOTOOLCHAIN downloads them from
+	toolchainModule = "golang.org/toolchain"
+	// sumDBURL is the Go checksum database, used when a proxy does not
+	// serve it itself
+	sumDBURL = "https://sum.golang.org"
+)
+
+// archive is a downloadable Go release archive, along with the
+// checksum it must match before it is extracted
+type archive struct {
+	url string
+	// sha256 is the hex encoded SHA-256 checksum of the file
+	sha256 string
+	// h1 is the checksum of the contents of a module zip, as
+	// recorded in go.sum files and the checksum database
+	h1 string
+}
+
+// mirror is a server Go releases can be downloaded from
+type mirror interface {
+	fmt.Stringer
+	// releases lists the Go releases available from the mirror
+	releases(src releaseSource) ([]GoVersion, error)
+	// archive locates the archive of Go version v for this platform
+	archive(v string, src releaseSource) (archive, error)
+}
+
+// releaseSource fetches releases from a chain of mirrors, falling back
+// to the next mirror whenever one fails. What it fetches is kept in
+// cache.
+type releaseSource struct {
+	// ctx cancels the requests of the operation the source is for
+	ctx     context.Context
+	client  HTTPClient
+	mirrors []mirror
+	cache   feedCache
+	// memo keeps what was fetched for the rest of the operation
+	memo *fetchMemo
+}
+
+// context returns the context requests are made with
+func (s releaseSource) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// mirrorChain returns the mirrors to try in order, go.dev if none are set
+func (s releaseSource) mirrorChain() []mirror {
+	if len(s.mirrors) == 0 {
+		return []mirror{feedMirror{url: defaultDownloadURL}}
+	}
+	return s.mirrors
+}
+
+// newMirrors creates the mirror chain for the module proxies in
+// goproxy, followed by the go.dev style servers in downloadURLs
+func newMirrors(goproxy, downloadURLs []string) []mirror {
+	var mirrors []mirror
+	for _, u := range goproxy {
+		mirrors = append(mirrors, proxyMirror{url: strings.TrimSuffix(u, "/")})
+	}
+	for _, u := range downloadURLs {
+		mirrors = append(mirrors, feedMirror{url: strings.TrimSuffix(u, "/")})
+	}
+	return mirrors
+}
+
+// parseGoProxy splits a list of module proxies in GOPROXY syntax.
+// Since gum only downloads from proxies, direct and off are skipped.
+func parseGoProxy(value string) []string {
+	var proxies []string
+	for _, proxy := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '|' }) {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" && proxy != "direct" && proxy != "off" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// tokenHosts returns the hosts the auth token of config is sent to,
+// which are those of the module proxies and download servers set up
+// explicitly. go.dev is part of the default chain, and never gets it.
+func tokenHosts(config Config) []string {
+	var hosts []string
+	for _, u := range slices.Concat(config.GoProxy, config.DownloadURLs) {
+		if strings.TrimSuffix(u, "/") == defaultDownloadURL {
+			continue
+		}
+		if parsed, err := url.Parse(u); err == nil && parsed.Hostname() != "" {
+			hosts = append(hosts, parsed.Hostname())
+		}
+	}
+	return hosts
+}
+
+// feedMirror serves releases in the layout of go.dev/dl: a JSON
+// feed listing every release, and a .tar.gz archive per platform
+type feedMirror struct {
+	url string
+}
+
+func (m feedMirror) String() string {
+	return m.url
+}
+
+func (m feedMirror) releases(src releaseSource) ([]GoVersion, error) {
+	return fetchFeed(src, m.url)
+}
+
+func (m feedMirror) archive(v string, src releaseSource) (archive, error) {
+	url, err := getDownloadURL(m.url, v)
+	if err != nil {
+		return archive{}, err
+	}
+
+	checksum, err := findChecksum(v, path.Base(url), src, m.url)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to look up checksum: %w", err)
+	}
+
+	return archive{url: url, sha256: checksum}, nil
+}
+
+// proxyMirror is a Go module proxy, serving every release as a
+// version of the golang.org/toolchain module
+type proxyMirror struct {
+	url string
+}
+
+func (m proxyMirror) String() string {
+	return m.url
+}
+
+func (m proxyMirror) releases(src releaseSource) ([]GoVersion, error) {
+	body, err := src.fetch(m.url + "/" + toolchainModule + "/@v/list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch versions: %w", err)
+	}
+
+	// Every release is listed once per platform
+	suffix := "." + runtime.GOOS + "-" + runtime.GOARCH
+	var versions []GoVersion
+	for _, modVersion := range strings.Fields(string(body)) {
+		v, ok := strings.CutPrefix(modVersion, "v0.0.1-")
+		if !ok {
+			continue
+		}
+		v, ok = strings.CutSuffix(v, suffix)
+		if !ok {
+			continue
+		}
+
+		parts, ok := parseVersion(v)
+		if !ok {
+			continue
+		}
+
+		versions = append(versions, GoVersion{
+			Version: v,
+			Stable:  parts.stage == stageRelease,
+			Files: []GoFile{{
+				Filename: modVersion + ".zip",
+				OS:       runtime.GOOS,
+				Arch:     runtime.GOARCH,
+				Version:  v,
+				Kind:     "archive",
+			}},
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[j].Version, versions[i].Version) // reverse for newest first
+	})
+
+	return versions, nil
+}
+
+func (m proxyMirror) archive(v string, src releaseSource) (archive, error) {
+	modVersion := toolchainModVersion(v)
+
+	// Proxies may serve the checksum database themselves, which is
+	// the only way to reach it from behind some firewalls
+	urls := []string{m.url + "/sumdb/" + sumDBName, sumDBURL}
+	h1, err := verifiedChecksum(src, urls, toolchainModule, modVersion)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to look up checksum: %w", err)
+	}
+
+	return archive{
+		url: m.url + "/" + toolchainModule + "/@v/" + modVersion + ".zip",
+		h1:  h1,
+	}, nil
+}
+
+// toolchainModVersion returns the version of the golang.org/toolchain
+// module holding Go version v for this platform
+func toolchainModVersion(v string) string {
+	return fmt.Sprintf("v0.0.1-%s.%s-%s", normaliseVersion(v), runtime.GOOS, runtime.GOARCH)
+}
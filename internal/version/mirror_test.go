@@ -0,0 +1,333 @@
+package version
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/dirhash"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// buildToolchainZip creates a golang.org/toolchain module zip holding
+// files for Go version v, and returns it with its h1 checksum
+func buildToolchainZip(t *testing.T, v string, files map[string]string) ([]byte, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		fw, err := zw.Create(toolchainModule + "@" + toolchainModVersion(v) + "/" + name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "toolchain.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	h1, err := dirhash.HashZip(path, dirhash.Hash1)
+	if err != nil {
+		t.Fatalf("Failed to hash zip: %v", err)
+	}
+
+	return buf.Bytes(), h1
+}
+
+// newMockServer serves the given bodies by URL, and 404 for anything else
+func newMockServer(bodies map[string][]byte) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, ok := bodies[req.URL.String()]
+			if !ok {
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Status:     "404 Not Found",
+					Body:       io.NopCloser(strings.NewReader("not found")),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			}, nil
+		},
+	}
+}
+
+func TestProxyMirrorReleases(t *testing.T) {
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	list := strings.Join([]string{
+		"v0.0.1-go1.21.0." + platform,
+		"v0.0.1-go1.22rc1." + platform,
+		"v0.0.1-go1.22.1." + platform,
+		"v0.0.1-go1.22.1.plan9-mips",
+		"v0.0.1-go1.23.0.plan9-mips",
+	}, "\n")
+
+	client := newMockServer(map[string][]byte{
+		"https://proxy.example.com/golang.org/toolchain/@v/list": []byte(list),
+	})
+
+	releases, err := proxyMirror{url: "https://proxy.example.com"}.releases(releaseSource{client: client})
+	if err != nil {
+		t.Fatalf("proxyMirror.releases() error = %v", err)
+	}
+
+	var got []string
+	for _, release := range releases {
+		got = append(got, fmt.Sprintf("%s %t", release.Version, release.Stable))
+	}
+
+	want := []string{"go1.22.1 true", "go1.22rc1 false", "go1.21.0 true"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("proxyMirror.releases() = %v, want %v", got, want)
+	}
+}
+
+// newTestSumDB serves a checksum database holding the h1 checksums of
+// the toolchain module versions in sums, signed with a new key. Unless
+// trusted is false, the key replaces the one of sum.golang.org.
+func newTestSumDB(t *testing.T, sums map[string]string, trusted bool) http.Handler {
+	t.Helper()
+
+	signer, verifier, err := note.GenerateKey(rand.Reader, sumDBName)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if trusted {
+		original := sumDBKey
+		sumDBKey = verifier
+		t.Cleanup(func() { sumDBKey = original })
+	}
+
+	server := sumdb.NewTestServer(signer, func(path, vers string) ([]byte, error) {
+		h1, ok := sums[vers]
+		if path != toolchainModule || !ok {
+			return nil, fmt.Errorf("no such module %s@%s", path, vers)
+		}
+		return []byte(fmt.Sprintf("%s %s %s\n%s %s/go.mod h1:mod=\n", path, vers, h1, path, vers)), nil
+	})
+	return sumdb.NewServer(server)
+}
+
+// withHandlers serves requests to urls starting with a prefix in
+// handlers with its handler, and everything else with client
+func withHandlers(client HTTPClient, handlers map[string]http.Handler) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			for prefix, handler := range handlers {
+				if rest, ok := strings.CutPrefix(req.URL.String(), prefix); ok {
+					inner := httptest.NewRequest(req.Method, rest, nil)
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, inner)
+					return recorder.Result(), nil
+				}
+			}
+			return client.Do(req)
+		},
+	}
+}
+
+func TestVersionManager_InstallFromProxy(t *testing.T) {
+	zipData, h1 := buildToolchainZip(t, "go1.22.1", map[string]string{
+		"bin/go":             "binary",
+		"pkg/tool/linux/vet": "binary",
+		"src/cmd/go/main.go": "package main",
+		"VERSION":            "go1.22.1",
+	})
+
+	modVersion := toolchainModVersion("go1.22.1")
+	zipURL := "https://proxy.example.com/golang.org/toolchain/@v/" + modVersion + ".zip"
+	proxySumDB := "https://proxy.example.com/sumdb/sum.golang.org"
+	forged := fmt.Sprintf("123\n%s %s %s\n\n— sum.golang.org signature\n", toolchainModule, modVersion, h1)
+
+	testCases := []struct {
+		name       string
+		bodies     map[string][]byte
+		sumDBs     func(t *testing.T) map[string]http.Handler
+		wantErrMsg string
+		wantOutput string
+	}{
+		{
+			name:   "checksum from proxy",
+			bodies: map[string][]byte{zipURL: zipData},
+			sumDBs: func(t *testing.T) map[string]http.Handler {
+				return map[string]http.Handler{proxySumDB: newTestSumDB(t, map[string]string{modVersion: h1}, true)}
+			},
+			wantOutput: "Verified checksum " + h1 + " from the Go checksum database",
+		},
+		{
+			name:   "checksum database fallback",
+			bodies: map[string][]byte{zipURL: zipData},
+			sumDBs: func(t *testing.T) map[string]http.Handler {
+				return map[string]http.Handler{sumDBURL: newTestSumDB(t, map[string]string{modVersion: h1}, true)}
+			},
+			wantOutput: "Verified checksum",
+		},
+		{
+			name:   "checksum mismatch",
+			bodies: map[string][]byte{zipURL: zipData},
+			sumDBs: func(t *testing.T) map[string]http.Handler {
+				return map[string]http.Handler{sumDBURL: newTestSumDB(t, map[string]string{modVersion: "h1:tampered="}, true)}
+			},
+			wantErrMsg: "checksum mismatch",
+		},
+		{
+			name: "unsigned lookup from proxy",
+			bodies: map[string][]byte{
+				proxySumDB + "/lookup/golang.org/toolchain@" + modVersion: []byte(forged),
+				zipURL: zipData,
+			},
+			sumDBs: func(t *testing.T) map[string]http.Handler {
+				return map[string]http.Handler{sumDBURL: newTestSumDB(t, map[string]string{modVersion: h1}, true)}
+			},
+			wantErrMsg: "failed to look up checksum",
+		},
+		{
+			name:   "lookup signed with another key",
+			bodies: map[string][]byte{zipURL: zipData},
+			sumDBs: func(t *testing.T) map[string]http.Handler {
+				trusted := newTestSumDB(t, map[string]string{modVersion: h1}, true)
+				return map[string]http.Handler{
+					proxySumDB: newTestSumDB(t, map[string]string{modVersion: h1}, false),
+					sumDBURL:   trusted,
+				}
+			},
+			wantErrMsg: "failed to look up checksum",
+		},
+		{
+			name:       "no checksum",
+			bodies:     map[string][]byte{zipURL: zipData},
+			sumDBs:     func(t *testing.T) map[string]http.Handler { return nil },
+			wantErrMsg: "failed to look up checksum",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := withHandlers(newMockServer(tc.bodies), tc.sumDBs(t))
+			installDir := filepath.Join(t.TempDir(), "versions")
+
+			manager := &VersionManager{
+				fs:         OSFileSystem{},
+				httpClient: client,
+				installDir: installDir,
+				mirrors:    []mirror{proxyMirror{url: "https://proxy.example.com"}},
+			}
+
+			var buf bytes.Buffer
+			err := manager.Install(context.Background(), "go1.22.1", InstallOptions{}, &buf)
+
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tc.wantErrMsg, err)
+				}
+				if _, err := os.Stat(filepath.Join(installDir, "go1.22.1")); !os.IsNotExist(err) {
+					t.Error("Expected nothing to be installed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VersionManager.Install() error = %v, output = %s", err, buf.String())
+			}
+
+			if !strings.Contains(buf.String(), tc.wantOutput) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tc.wantOutput, buf.String())
+			}
+
+			for name, wantPerm := range map[string]os.FileMode{
+				"bin/go":             0755,
+				"pkg/tool/linux/vet": 0755,
+				"VERSION":            0644,
+			} {
+				info, err := os.Stat(filepath.Join(installDir, "go1.22.1", name))
+				if err != nil {
+					t.Fatalf("Expected %s to be installed, got error: %v", name, err)
+				}
+				if info.Mode().Perm() != wantPerm {
+					t.Errorf("%s mode = %v, want %v", name, info.Mode().Perm(), wantPerm)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionManager_InstallFallback(t *testing.T) {
+	release := newMockRelease(t, "go1.24.2")
+
+	var requested []string
+	client := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requested = append(requested, req.URL.Host)
+			if req.URL.Host == "mirror.example.com" {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Status:     "503 Service Unavailable",
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			}
+			return release.Do(req)
+		},
+	}
+
+	installDir := filepath.Join(t.TempDir(), "versions")
+	manager := &VersionManager{
+		fs:         OSFileSystem{},
+		httpClient: client,
+		installDir: installDir,
+		mirrors:    newMirrors(nil, []string{"https://mirror.example.com/go/", defaultDownloadURL}),
+	}
+
+	var buf bytes.Buffer
+	if err := manager.Install(context.Background(), "go1.24.2", InstallOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Install() error = %v, output = %s", err, buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "Download from https://mirror.example.com/go failed") {
+		t.Errorf("Expected output to mention the failed mirror, got '%s'", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Trying https://go.dev/dl instead") {
+		t.Errorf("Expected output to mention the next mirror, got '%s'", buf.String())
+	}
+	if requested[0] != "mirror.example.com" {
+		t.Errorf("Expected the first mirror to be tried first, got %v", requested)
+	}
+
+	if !manager.isComplete(filepath.Join(installDir, "go1.24.2")) {
+		t.Error("Expected go1.24.2 to be installed")
+	}
+}
+
+func TestParseGoProxy(t *testing.T) {
+	got := parseGoProxy("https://a.example.com, https://b.example.com|direct,off")
+	want := []string{"https://a.example.com", "https://b.example.com"}
+
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("parseGoProxy() = %v, want %v", got, want)
+	}
+}
+
+func TestSumDBKey(t *testing.T) {
+	if _, err := note.NewVerifier(sumDBKey); err != nil {
+		t.Errorf("note.NewVerifier(sumDBKey) error = %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package version
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// maxStatusWidth keeps status lines from wrapping, which would throw
+// off redrawing them in place
+const maxStatusWidth = 60
+
+// progressBoard shows the output of several installs running at once.
+// On a terminal, every install has a status line that is redrawn in
+// place, with the lines they print scrolling by above. Elsewhere, only
+// the printed lines are shown, each marked with its install.
+type progressBoard struct {
+	mu sync.Mutex
+	w  io.Writer
+	// live redraws the status lines in place
+	live   bool
+	labels []string
+	status []string
+	// drawn is how many status lines are on the screen
+	drawn int
+}
+
+// newProgressBoard creates a board with a status line for each label
+func newProgressBoard(w io.Writer, labels []string) *progressBoard {
+	return &progressBoard{
+		w:      w,
+		live:   isTerminal(w),
+		labels: labels,
+		status: make([]string, len(labels)),
+	}
+}
+
+// writer returns the writer for the install with label i. Lines it
+// receives are printed, while text that is rewritten after a carriage
+// return, like download progress, becomes the status of the install.
+func (b *progressBoard) writer(i int) io.Writer {
+	return &boardWriter{board: b, index: i}
+}
+
+// setStatus sets the status line of the install with label i
+func (b *progressBoard) setStatus(i int, status string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.status[i] = status
+	if b.live {
+		b.redraw("")
+	}
+}
+
+// println prints line for the install with label i
+func (b *progressBoard) println(i int, line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	line = fmt.Sprintf("[%s] %s", b.labels[i], line)
+	if b.live {
+		b.redraw(line)
+		return
+	}
+	fmt.Fprintln(b.w, line)
+}
+
+// redraw replaces the status lines on the screen, printing line above
+// them if it is set
+func (b *progressBoard) redraw(line string) {
+	if b.drawn > 0 {
+		// Move up to the first status line and clear the rest of the screen
+		fmt.Fprintf(b.w, "\x1b[%dA\x1b[J", b.drawn)
+	}
+	if line != "" {
+		fmt.Fprintln(b.w, line)
+	}
+
+	width := 0
+	for _, label := range b.labels {
+		width = max(width, len(label))
+	}
+	for i, label := range b.labels {
+		status := b.status[i]
+		if len(status) > maxStatusWidth {
+			status = status[:maxStatusWidth-3] + "..."
+		}
+		fmt.Fprintf(b.w, "%-*s  %s\n", width, label, status)
+	}
+	b.drawn = len(b.labels)
+}
+
+// boardWriter splits what one install writes into printed lines and
+// status updates
+type boardWriter struct {
+	board *progressBoard
+	index int
+	// pending is text not terminated yet
+	pending []byte
+	// rewriting is set after a carriage return, when the text that
+	// follows replaces the previous status
+	rewriting bool
+}
+
+func (bw *boardWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		switch c {
+		case '\r', '\n':
+			text := strings.TrimSpace(string(bw.pending))
+			bw.pending = bw.pending[:0]
+
+			// A newline ends a status that is rewritten as well
+			if bw.rewriting {
+				if text != "" {
+					bw.board.setStatus(bw.index, text)
+				}
+			} else if text != "" {
+				bw.board.println(bw.index, text)
+			}
+			bw.rewriting = c == '\r'
+		default:
+			bw.pending = append(bw.pending, c)
+		}
+	}
+
+	// Progress is rewritten without a line ending, so it is shown
+	// right away
+	if bw.rewriting && len(bw.pending) > 0 {
+		bw.board.setStatus(bw.index, strings.TrimSpace(string(bw.pending)))
+	}
+	return len(p), nil
+}
+
+// isTerminal reports whether w writes to a terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,166 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envVersionVar records the version gum env activated in a shell,
+// so a later run knows which settings it owns
+const envVersionVar = "GUM_ENV_VERSION"
+
+// supportedShells lists the shells gum can generate code for
+var supportedShells = []string{"bash", "zsh", "fish"}
+
+var hookScripts = map[string]string{
+	"bash": `_gum_hook() {
+  local previous_exit_status=$?
+  if [[ "$PWD" != "${_GUM_LAST_PWD:-}" ]]; then
+    _GUM_LAST_PWD="$PWD"
+    eval "$(command gum env --shell bash)"
+  fi
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_gum_hook;"* ]]; then
+  PROMPT_COMMAND="_gum_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`,
+	"zsh": `_gum_hook() {
+  eval "$(command gum env --shell zsh)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _gum_hook
+_gum_hook
+`,
+	"fish": `function _gum_hook --on-variable PWD
+  command gum env --shell fish | source
+end
+_gum_hook
+`,
+}
+
+// Hook writes a snippet for shell that runs 'gum env' on every change
+// of directory, so each shell uses the version its project asks for
+func Hook(shell string, w io.Writer) error {
+	script, ok := hookScripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q, expected one of %s", shell, strings.Join(supportedShells, ", "))
+	}
+
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// Env writes shell code that puts version v first on PATH and sets
+// GOROOT for the current shell only, leaving the global links alone.
+// Without a version, the version is detected as for Use. When nothing
+// is detected, settings made by an earlier run are undone instead.
+func (m *VersionManager) Env(ctx context.Context, v, shell string, w io.Writer) error {
+	if _, ok := hookScripts[shell]; !ok {
+		return fmt.Errorf("unsupported shell %q, expected one of %s", shell, strings.Join(supportedShells, ", "))
+	}
+
+	if v == "" {
+		requested, err := detectVersion(m.fs)
+		if err != nil {
+			// Outside of a project, fall back to the global version
+			m.writeEnvReset(shell, w)
+			return nil
+		}
+
+		v, err = m.resolveInstalledVersion(requested)
+		if err != nil || v == "" || !m.isComplete(filepath.Join(m.installDir, normaliseVersion(v))) {
+			// Warn from within the shell, since our output is evaluated
+			m.writeEnvReset(shell, w)
+			fmt.Fprintf(w, "echo %s >&2\n", shellQuote(shell, fmt.Sprintf(
+				"gum: Go %s requested by %s is not installed", requested.Version, requested.Source)))
+			return nil
+		}
+	}
+
+	// Our output is evaluated by the shell, so only the result of
+	// resolving an alias is of interest
+	v, err := m.resolveSpec(ctx, v, resolveOptions{preferInstalled: true}, m.source(ctx, io.Discard), io.Discard)
+	if err != nil {
+		return err
+	}
+
+	v = normaliseVersion(v)
+	versionDir := filepath.Join(m.installDir, v)
+	if !m.isComplete(versionDir) {
+		return fmt.Errorf("Go %s is not installed. Use 'gum install %s' first", v, v)
+	}
+
+	path := append([]string{filepath.Join(versionDir, "bin")}, m.pathWithoutVersions()...)
+	writeExport(shell, w, "GOROOT", versionDir)
+	writeExport(shell, w, envVersionVar, v)
+	writeExport(shell, w, "PATH", path...)
+	return nil
+}
+
+// resolveInstalledVersion is like resolveRequestedVersion, but only
+// considers installed versions so it never touches the network
+func (m *VersionManager) resolveInstalledVersion(req requestedVersion) (string, error) {
+	if isMajorMinorVersion(strings.TrimPrefix(req.Version, "go")) {
+		return m.newestInstalled(req.Version)
+	}
+	return req.Version, nil
+}
+
+// writeEnvReset undoes the settings of an earlier 'gum env', if any
+func (m *VersionManager) writeEnvReset(shell string, w io.Writer) {
+	if os.Getenv(envVersionVar) == "" {
+		return
+	}
+
+	writeUnset(shell, w, "GOROOT")
+	writeUnset(shell, w, envVersionVar)
+	writeExport(shell, w, "PATH", m.pathWithoutVersions()...)
+}
+
+// pathWithoutVersions returns the entries of PATH, minus those
+// pointing into a version installed by gum
+func (m *VersionManager) pathWithoutVersions() []string {
+	var path []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" && !isWithin(m.installDir, dir) {
+			path = append(path, dir)
+		}
+	}
+	return path
+}
+
+func writeExport(shell string, w io.Writer, name string, values ...string) {
+	if shell == "fish" {
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = shellQuote(shell, value)
+		}
+		fmt.Fprintf(w, "set -gx %s %s;\n", name, strings.Join(quoted, " "))
+		return
+	}
+
+	value := strings.Join(values, string(os.PathListSeparator))
+	fmt.Fprintf(w, "export %s=%s;\n", name, shellQuote(shell, value))
+}
+
+func writeUnset(shell string, w io.Writer, name string) {
+	if shell == "fish" {
+		fmt.Fprintf(w, "set -e %s;\n", name)
+		return
+	}
+	fmt.Fprintf(w, "unset %s;\n", name)
+}
+
+// shellQuote quotes s as a single word for shell
+func shellQuote(shell, s string) string {
+	if shell == "fish" {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,172 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	for _, shell := range supportedShells {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Hook(shell, &buf); err != nil {
+				t.Fatalf("Hook(%q) error = %v", shell, err)
+			}
+
+			if !strings.Contains(buf.String(), "gum env --shell "+shell) {
+				t.Errorf("Expected hook to run 'gum env --shell %s', got '%s'", shell, buf.String())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := Hook("tcsh", &buf); err == nil {
+		t.Error("Expected error for unsupported shell, got nil")
+	}
+}
+
+func TestVersionManager_Env(t *testing.T) {
+	installedFS := map[string]bool{
+		"/mock/home/.gum/versions/go1.22.4":        true,
+		"/mock/home/.gum/versions/go1.22.4/bin/go": true,
+	}
+
+	tests := []struct {
+		name         string
+		version      string
+		shell        string
+		envVersion   string
+		files        map[string]string
+		wantErr      bool
+		wantOutput   []string
+		unwantOutput []string
+	}{
+		{
+			name:    "explicit version",
+			version: "1.22.4",
+			shell:   "bash",
+			wantOutput: []string{
+				"export GOROOT='/mock/home/.gum/versions/go1.22.4';\n",
+				"export GUM_ENV_VERSION='go1.22.4';\n",
+				"export PATH='/mock/home/.gum/versions/go1.22.4/bin:/usr/bin:/mock/home/.gum/bin';\n",
+			},
+		},
+		{
+			name:    "fish syntax",
+			version: "1.22.4",
+			shell:   "fish",
+			wantOutput: []string{
+				"set -gx GOROOT '/mock/home/.gum/versions/go1.22.4';\n",
+				"set -gx PATH '/mock/home/.gum/versions/go1.22.4/bin' '/usr/bin' '/mock/home/.gum/bin';\n",
+			},
+		},
+		{
+			name:    "explicit version not installed",
+			version: "1.21.0",
+			shell:   "bash",
+			wantErr: true,
+		},
+		{
+			name:  "detected release series",
+			shell: "zsh",
+			files: map[string]string{
+				"/mock/project/go.mod": "module example.com/m\n\ngo 1.22\n",
+			},
+			wantOutput: []string{"export GOROOT='/mock/home/.gum/versions/go1.22.4';\n"},
+		},
+		{
+			name:  "detected version not installed",
+			shell: "bash",
+			files: map[string]string{
+				"/mock/project/.go-version": "1.21.0\n",
+			},
+			wantOutput: []string{"echo 'gum: Go 1.21.0 requested by /mock/project/.go-version is not installed' >&2\n"},
+		},
+		{
+			name:       "outside of a project resets earlier settings",
+			shell:      "bash",
+			envVersion: "go1.22.4",
+			wantOutput: []string{
+				"unset GOROOT;\n",
+				"unset GUM_ENV_VERSION;\n",
+				"export PATH='/usr/bin:/mock/home/.gum/bin';\n",
+			},
+		},
+		{
+			name:         "outside of a project without earlier settings",
+			shell:        "bash",
+			unwantOutput: []string{"GOROOT", "PATH"},
+		},
+		{
+			name:    "unsupported shell",
+			version: "1.22.4",
+			shell:   "tcsh",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", "/mock/home/.gum/versions/go1.21.0/bin:/usr/bin:/mock/home/.gum/bin")
+			t.Setenv("GUM_ENV_VERSION", tt.envVersion)
+			t.Setenv("GUM_GO_VERSION", "")
+
+			mockFS := &MockFileSystem{
+				ExistingFiles: map[string]bool{},
+				FileContents:  tt.files,
+			}
+			for name := range installedFS {
+				mockFS.ExistingFiles[name] = true
+			}
+			for name := range tt.files {
+				mockFS.ExistingFiles[name] = true
+			}
+
+			manager := &VersionManager{
+				fs:         mockFS,
+				installDir: "/mock/home/.gum/versions",
+				binDir:     "/mock/home/.gum/bin",
+			}
+
+			var buf bytes.Buffer
+			err := manager.Env(context.Background(), tt.version, tt.shell, &buf)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VersionManager.Env() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected output to contain '%s', got '%s'", want, buf.String())
+				}
+			}
+
+			for _, unwant := range tt.unwantOutput {
+				if strings.Contains(buf.String(), unwant) {
+					t.Errorf("Expected output not to contain '%s', got '%s'", unwant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	values := []string{"plain", "with space", "it's", `back\slash`, "$HOME"}
+	for _, value := range values {
+		out, err := exec.Command(bash, "-c", "printf %s "+shellQuote("bash", value)).Output()
+		if err != nil {
+			t.Fatalf("bash failed for %q: %v", value, err)
+		}
+		if string(out) != value {
+			t.Errorf("shellQuote(%q) round trip = %q", value, string(out))
+		}
+	}
+}
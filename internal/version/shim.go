@@ -0,0 +1,255 @@
+package version
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// shimMarker identifies the shims gum writes into the bin directory
+	shimMarker = "# gum shim"
+	// defaultVersionFile holds the version shims fall back to when
+	// nothing is requested for the current directory. Its existence
+	// means shim mode is enabled.
+	defaultVersionFile = "version"
+)
+
+const shimTemplate = `#!/bin/sh
+` + shimMarker + `, managed by 'gum shims', do not edit
+exec %s shim-exec %s "$@"
+`
+
+// EnableShims replaces the links in ~/.gum/bin with shims that pick
+// the Go version for the current directory on every invocation.
+// The currently active version becomes the default.
+func (m *VersionManager) EnableShims(ctx context.Context, w io.Writer) error {
+	binDir := m.binDir
+
+	if m.shimsEnabled() {
+		fmt.Fprintln(w, "Shims are already enabled")
+		return nil
+	}
+
+	v := m.activeVersion(binDir)
+	if v == "" {
+		return fmt.Errorf("no active Go version, use 'gum use <version>' first")
+	}
+
+	gumPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate gum executable: %w", err)
+	}
+
+	if err := m.setDefaultVersion(v, gumPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Enabled shims in %s with Go %s as the default version\n", binDir, v)
+	return nil
+}
+
+// DisableShims replaces the shims in ~/.gum/bin with links to the default version
+func (m *VersionManager) DisableShims(ctx context.Context, w io.Writer) error {
+	binDir := m.binDir
+
+	if !m.shimsEnabled() {
+		fmt.Fprintln(w, "Shims are not enabled")
+		return nil
+	}
+
+	v, err := m.defaultVersion()
+	if err != nil {
+		return err
+	}
+
+	if err := m.removeShims(binDir, nil); err != nil {
+		return fmt.Errorf("failed to remove shims: %w", err)
+	}
+
+	srcDir := filepath.Join(m.installDir, v, "bin")
+	tools, err := m.toolchainBinaries(srcDir)
+	if err != nil {
+		return fmt.Errorf("Go binaries not found in %s", srcDir)
+	}
+
+	if _, err := m.linkToolchain(binDir, srcDir, tools); err != nil {
+		return fmt.Errorf("failed to set Go %s as active: %w", v, err)
+	}
+
+	if err := m.fs.Remove(m.defaultVersionPath()); err != nil {
+		return fmt.Errorf("failed to remove default version: %w", err)
+	}
+
+	fmt.Fprintf(w, "Disabled shims, Go %s is the active version\n", v)
+	return nil
+}
+
+// ShimExec runs tool from the Go version requested for the current
+// directory, or the default version if nothing is requested. It is
+// what the shims in ~/.gum/bin call, and only returns on failure.
+func (m *VersionManager) ShimExec(ctx context.Context, tool string, args []string, stderr io.Writer) error {
+	toolPath, versionDir, err := m.shimTarget(tool, stderr)
+	if err != nil {
+		return err
+	}
+
+	env := setEnv(os.Environ(), "GOROOT", versionDir)
+	return execTool(toolPath, args, env)
+}
+
+// shimTarget finds the binary a shim for tool should run
+func (m *VersionManager) shimTarget(tool string, stderr io.Writer) (string, string, error) {
+	v := ""
+	if requested, err := detectVersion(m.fs); err == nil {
+		resolved, err := m.resolveInstalledVersion(requested)
+		if err == nil && resolved != "" && m.isComplete(filepath.Join(m.installDir, normaliseVersion(resolved))) {
+			v = normaliseVersion(resolved)
+		} else {
+			fmt.Fprintf(stderr, "gum: Go %s requested by %s is not installed, using the default version\n", requested.Version, requested.Source)
+		}
+	}
+
+	if v == "" {
+		defaultVersion, err := m.defaultVersion()
+		if err != nil {
+			return "", "", err
+		}
+		v = defaultVersion
+	}
+
+	versionDir := filepath.Join(m.installDir, v)
+	toolPath := filepath.Join(versionDir, "bin", tool)
+	if _, err := m.fs.Stat(toolPath); err != nil {
+		return "", "", fmt.Errorf("%s is not provided by Go %s", tool, v)
+	}
+
+	return toolPath, versionDir, nil
+}
+
+// setDefaultVersion makes v the version shims fall back to, and writes
+// a shim running gumPath for each of its tools
+func (m *VersionManager) setDefaultVersion(v, gumPath string) error {
+	binDir := m.binDir
+
+	tools, err := m.toolchainBinaries(filepath.Join(m.installDir, v, "bin"))
+	if err != nil {
+		return fmt.Errorf("Go binaries not found in %s", filepath.Join(m.installDir, v))
+	}
+
+	if err := m.removeShims(binDir, tools); err != nil {
+		return fmt.Errorf("failed to remove shims: %w", err)
+	}
+
+	for _, tool := range tools {
+		shimPath := filepath.Join(binDir, tool)
+
+		// Replace links to a version, but never a file we do not manage
+		if _, err := m.fs.ReadLink(shimPath); err == nil {
+			if err := m.fs.Remove(shimPath); err != nil {
+				return err
+			}
+		} else if _, err := m.fs.Stat(shimPath); err == nil && !m.isShim(shimPath) {
+			return fmt.Errorf("%s exists and is not managed by gum", shimPath)
+		}
+
+		shim := fmt.Sprintf(shimTemplate, shellQuote("sh", gumPath), shellQuote("sh", tool))
+		if err := m.fs.WriteFile(shimPath, []byte(shim), 0755); err != nil {
+			return fmt.Errorf("failed to write shim %s: %w", shimPath, err)
+		}
+	}
+
+	if err := m.fs.WriteFile(m.defaultVersionPath(), []byte(v+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write default version: %w", err)
+	}
+
+	return nil
+}
+
+// removeShims removes the shims in binDir for tools not listed in keep
+func (m *VersionManager) removeShims(binDir string, keep []string) error {
+	entries, err := m.fs.ReadDir(binDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(binDir, entry.Name())
+		if entry.IsDir() || !m.isShim(path) {
+			continue
+		}
+
+		kept := false
+		for _, tool := range keep {
+			kept = kept || tool == entry.Name()
+		}
+		if kept {
+			continue
+		}
+
+		if err := m.fs.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isShim reports whether path is a shim written by gum
+func (m *VersionManager) isShim(path string) bool {
+	if _, err := m.fs.ReadLink(path); err == nil {
+		return false
+	}
+
+	file, err := m.fs.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < 2 && scanner.Scan(); i++ {
+		if strings.HasPrefix(scanner.Text(), shimMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// shimsEnabled reports whether ~/.gum/bin holds shims rather than links
+func (m *VersionManager) shimsEnabled() bool {
+	_, err := m.fs.Stat(m.defaultVersionPath())
+	return err == nil
+}
+
+// defaultVersion reads the version shims fall back to
+func (m *VersionManager) defaultVersion() (string, error) {
+	req, err := readPinFile(m.fs, m.defaultVersionPath())
+	if err != nil {
+		return "", fmt.Errorf("no default Go version, use 'gum use <version>' first")
+	}
+	return normaliseVersion(req.Version), nil
+}
+
+func (m *VersionManager) defaultVersionPath() string {
+	home, _ := m.fs.UserHomeDir()
+	return filepath.Join(home, ".gum", defaultVersionFile)
+}
+
+// setEnv returns env with key set to value
+func setEnv(env []string, key, value string) []string {
+	result := make([]string, 0, len(env)+1)
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, key+"=") {
+			result = append(result, kv)
+		}
+	}
+	return append(result, key+"="+value)
+}
@@ -0,0 +1,124 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFiles creates each of files below root with the given content
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestVersionManager_Shims(t *testing.T) {
+	t.Setenv("GUM_GO_VERSION", "")
+	t.Setenv("GOWORK", "off")
+
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	project := filepath.Join(root, "project")
+	elsewhere := filepath.Join(root, "elsewhere")
+
+	writeTestFiles(t, root, map[string]string{
+		"home/.gum/versions/go1.21.0/bin/go":    "#!/bin/sh\n",
+		"home/.gum/versions/go1.21.0/bin/gofmt": "#!/bin/sh\n",
+		"home/.gum/versions/go1.22.4/bin/go":    "#!/bin/sh\n",
+		"home/.gum/bin/gum":                     "#!/bin/sh\n",
+		"project/.go-version":                   "1.22.4\n",
+		"elsewhere/README":                      "\n",
+	})
+
+	fs := &tempHomeFileSystem{home: home, wd: elsewhere}
+	manager := &VersionManager{
+		fs:         fs,
+		installDir: filepath.Join(home, ".gum", "versions"),
+		binDir:     filepath.Join(home, ".gum", "bin"),
+	}
+	binDir := filepath.Join(home, ".gum", "bin")
+
+	var buf bytes.Buffer
+	if err := manager.Use(context.Background(), "go1.21.0", UseOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Use() error = %v", err)
+	}
+
+	if err := manager.EnableShims(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.EnableShims() error = %v", err)
+	}
+
+	for _, tool := range []string{"go", "gofmt"} {
+		if !manager.isShim(filepath.Join(binDir, tool)) {
+			t.Errorf("Expected %s to be a shim", tool)
+		}
+	}
+	if manager.isShim(filepath.Join(binDir, "gum")) {
+		t.Error("Expected gum itself not to be a shim")
+	}
+
+	// Outside of a project, shims fall back to the default version
+	toolPath, _, err := manager.shimTarget("gofmt", &buf)
+	if err != nil {
+		t.Fatalf("shimTarget() error = %v", err)
+	}
+	if want := filepath.Join(manager.installDir, "go1.21.0", "bin", "gofmt"); toolPath != want {
+		t.Errorf("shimTarget() = %s, want %s", toolPath, want)
+	}
+
+	// Inside of a project, shims use the version it requests
+	fs.wd = project
+	toolPath, versionDir, err := manager.shimTarget("go", &buf)
+	if err != nil {
+		t.Fatalf("shimTarget() error = %v", err)
+	}
+	if want := filepath.Join(manager.installDir, "go1.22.4", "bin", "go"); toolPath != want {
+		t.Errorf("shimTarget() = %s, want %s", toolPath, want)
+	}
+	if want := filepath.Join(manager.installDir, "go1.22.4"); versionDir != want {
+		t.Errorf("shimTarget() GOROOT = %s, want %s", versionDir, want)
+	}
+
+	if _, _, err := manager.shimTarget("gofmt", &buf); err == nil || !strings.Contains(err.Error(), "not provided by Go go1.22.4") {
+		t.Errorf("Expected error for missing tool, got %v", err)
+	}
+
+	// Using a version with shims enabled only changes the default
+	buf.Reset()
+	if err := manager.Use(context.Background(), "go1.22.4", UseOptions{}, &buf); err != nil {
+		t.Fatalf("VersionManager.Use() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Successfully set Go go1.22.4 as the default version") {
+		t.Errorf("Expected output to mention default version, got '%s'", buf.String())
+	}
+	if _, err := os.Lstat(filepath.Join(binDir, "gofmt")); !os.IsNotExist(err) {
+		t.Error("Expected gofmt shim to be removed for a version without it")
+	}
+
+	if err := manager.DisableShims(context.Background(), &buf); err != nil {
+		t.Fatalf("VersionManager.DisableShims() error = %v", err)
+	}
+
+	target, err := os.Readlink(filepath.Join(binDir, "go"))
+	if err != nil {
+		t.Fatalf("Expected go to be a link again, got error: %v", err)
+	}
+	if want := filepath.Join(manager.installDir, "go1.22.4", "bin", "go"); target != want {
+		t.Errorf("Link target = %s, want %s", target, want)
+	}
+
+	if manager.shimsEnabled() {
+		t.Error("Expected shims to be disabled")
+	}
+}
@@ -2,10 +2,11 @@ package version
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,10 +31,13 @@ type progressWriter struct {
 	lastUpdate time.Time
 }
 
-func newProgressWriter(w io.Writer, total int64) *progressWriter {
+// newProgressWriter reports progress towards total bytes, of which
+// done were received before, by an earlier attempt
+func newProgressWriter(w io.Writer, done, total int64) *progressWriter {
 	return &progressWriter{
 		w:          w,
 		total:      total,
+		progress:   done,
 		startTime:  time.Now(),
 		lastUpdate: time.Now(),
 	}
@@ -91,17 +95,28 @@ func formatBytes(bytes int64) string {
 }
 
 // resolveVersion resolves a version string to the full version
-// If the version is already complete (e.g., "1.23.4"), it returns as-is
-// If the version is major.minor (e.g., "1.23"), it finds the latest patch version
-func resolveVersion(v string, client HTTPClient) (string, error) {
+// If the version is already complete (e.g., "1.23.4" or "1.26rc2"), it returns as-is
+// If the version is major.minor (e.g., "1.23"), it finds the latest patch version,
+// only considering pre-releases when prerelease is set
+// The aliases latest, stable and oldstable, and a bare major version
+// (e.g., "1"), resolve to the newest release they stand for
+func resolveVersion(v string, prerelease bool, src releaseSource) (string, error) {
 	cleanVersion := strings.TrimPrefix(v, "go")
 
-	if isCompleteVersion(cleanVersion) {
+	if isCompleteVersion(cleanVersion) || isPrereleaseVersion(cleanVersion) {
 		return v, nil
 	}
 
+	if isReleaseAlias(v) || isMajorVersion(cleanVersion) {
+		latestVersion, err := resolveReleaseAlias(cleanVersion, prerelease, src)
+		if err != nil {
+			return "", fmt.Errorf("failed to find the version %s stands for: %w", cleanVersion, err)
+		}
+		return latestVersion, nil
+	}
+
 	if isMajorMinorVersion(cleanVersion) {
-		latestVersion, err := findLatestPatchVersion(cleanVersion, client)
+		latestVersion, err := findLatestPatchVersion(cleanVersion, prerelease, src)
 		if err != nil {
 			return "", fmt.Errorf("failed to find latest patch version for %s: %w", cleanVersion, err)
 		}
@@ -112,62 +127,105 @@ func resolveVersion(v string, client HTTPClient) (string, error) {
 	return v, nil
 }
 
+var (
+	// Regex for major.minor.patch format (e.g., "1.23.4")
+	completeVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	// Regex for major.minor format (e.g., "1.23")
+	majorMinorRegex = regexp.MustCompile(`^\d+\.\d+$`)
+	// Regex for pre-release format (e.g., "1.26rc2" or "1.26beta1")
+	prereleaseRegex = regexp.MustCompile(`^\d+\.\d+(beta|rc)\d+$`)
+	// Regex splitting any of the above into its parts
+	versionPartsRegex = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:(beta|rc)(\d+))?$`)
+)
+
 // isCompleteVersion checks if a version string is in major.minor.patch format
 func isCompleteVersion(v string) bool {
-	// Regex for major.minor.patch format (e.g., "1.23.4")
-	completeVersionRegex := regexp.MustCompile(`^\d+\.\d+\.\d+$`)
 	return completeVersionRegex.MatchString(v)
 }
 
 // isMajorMinorVersion checks if a version string is in major.minor format
 func isMajorMinorVersion(v string) bool {
-	// Regex for major.minor format (e.g., "1.23")
-	majorMinorRegex := regexp.MustCompile(`^\d+\.\d+$`)
 	return majorMinorRegex.MatchString(v)
 }
 
-// compareVersions compares two Go version strings semantically
-// Returns true if a < b
-func compareVersions(a, b string) bool {
-	a = strings.TrimPrefix(a, "go")
-	b = strings.TrimPrefix(b, "go")
+// isPrereleaseVersion checks if a version string is a beta or release candidate
+func isPrereleaseVersion(v string) bool {
+	return prereleaseRegex.MatchString(strings.TrimPrefix(v, "go"))
+}
 
-	aParts := strings.Split(a, ".")
-	bParts := strings.Split(b, ".")
+// versionParts holds the components of a Go version string
+type versionParts struct {
+	major, minor, patch int
+	// stage orders betas before release candidates before releases
+	stage    int
+	stageNum int
+}
 
-	for i := 0; i < 3; i++ {
-		var aVal, bVal int
+const (
+	stageBeta = iota
+	stageRC
+	stageRelease
+)
 
-		if i < len(aParts) {
-			aVal, _ = strconv.Atoi(aParts[i])
-		}
-		if i < len(bParts) {
-			bVal, _ = strconv.Atoi(bParts[i])
-		}
+// parseVersion splits a Go version string into its parts
+func parseVersion(v string) (versionParts, bool) {
+	match := versionPartsRegex.FindStringSubmatch(strings.TrimPrefix(v, "go"))
+	if match == nil {
+		return versionParts{}, false
+	}
 
-		if aVal != bVal {
-			return aVal < bVal
-		}
+	parts := versionParts{stage: stageRelease}
+	parts.major, _ = strconv.Atoi(match[1])
+	parts.minor, _ = strconv.Atoi(match[2])
+	parts.patch, _ = strconv.Atoi(match[3])
+
+	switch match[4] {
+	case "beta":
+		parts.stage = stageBeta
+	case "rc":
+		parts.stage = stageRC
 	}
+	parts.stageNum, _ = strconv.Atoi(match[5])
+
+	return parts, true
+}
+
+// compareVersions compares two Go version strings semantically
+// Returns true if a < b, where 1.26beta1 < 1.26rc1 < 1.26rc2 < 1.26.0
+func compareVersions(a, b string) bool {
+	aParts, _ := parseVersion(a)
+	bParts, _ := parseVersion(b)
+	return compareParts(aParts, bParts) < 0
+}
 
-	return false // versions are equal
+// compareParts returns -1 if a < b, 0 if a == b and +1 if a > b
+func compareParts(a, b versionParts) int {
+	aVals := []int{a.major, a.minor, a.patch, a.stage, a.stageNum}
+	bVals := []int{b.major, b.minor, b.patch, b.stage, b.stageNum}
+	return slices.Compare(aVals, bVals)
 }
 
 // findLatestPatchVersion finds the latest patch version for a given major.minor version
-func findLatestPatchVersion(majorMinor string, client HTTPClient) (string, error) {
-	versions, err := fetchAvailableVersions(client)
+// Pre-releases of that version are only considered when prerelease is set
+func findLatestPatchVersion(majorMinor string, prerelease bool, src releaseSource) (string, error) {
+	versions, err := fetchAvailableVersions(src)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch available versions: %w", err)
 	}
 
+	want, _ := parseVersion(majorMinor)
+
 	// Filter versions that match the major.minor pattern
 	var matchingVersions []string
-	prefix := "go" + majorMinor + "."
-
 	for _, version := range versions {
-		if strings.HasPrefix(version, prefix) {
-			matchingVersions = append(matchingVersions, version)
+		parts, ok := parseVersion(version)
+		if !ok || parts.major != want.major || parts.minor != want.minor {
+			continue
 		}
+		if parts.stage != stageRelease && !prerelease {
+			continue
+		}
+		matchingVersions = append(matchingVersions, version)
 	}
 
 	if len(matchingVersions) == 0 {
@@ -181,41 +239,66 @@ func findLatestPatchVersion(majorMinor string, client HTTPClient) (string, error
 	return matchingVersions[0], nil
 }
 
-// GoVersion represents a Go version from the API
-type GoVersion struct {
-	Version string `json:"version"`
-	Stable  bool   `json:"stable"`
-	Files   []struct {
-		Filename string `json:"filename"`
-		OS       string `json:"os"`
-		Arch     string `json:"arch"`
-		Version  string `json:"version"`
-		Kind     string `json:"kind"`
-	} `json:"files"`
-}
-
-// fetchAvailableVersions fetches the list of available Go versions
-func fetchAvailableVersions(client HTTPClient) ([]string, error) {
-	req, err := http.NewRequest("GET", BaseURL+"/?mode=json", nil)
+// supportedReleases is how many of the newest major releases the Go
+// project supports with security fixes
+const supportedReleases = 2
+
+// findSupportedVersions returns the major.minor versions of the Go
+// releases currently supported, newest first
+func findSupportedVersions(src releaseSource) ([]string, error) {
+	versions, err := fetchAvailableVersions(src)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to fetch available versions: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "gum/1.0")
+	var supported []string
+	for _, version := range versions {
+		parts, ok := parseVersion(version)
+		if !ok || parts.stage != stageRelease {
+			continue
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch versions: %w", err)
+		majorMinor := fmt.Sprintf("%d.%d", parts.major, parts.minor)
+		if !slices.Contains(supported, majorMinor) {
+			supported = append(supported, majorMinor)
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch versions with status %s", resp.Status)
+	sort.Slice(supported, func(i, j int) bool {
+		return compareVersions(supported[j], supported[i]) // reverse for newest first
+	})
+
+	if len(supported) == 0 {
+		return nil, fmt.Errorf("no stable versions found")
 	}
 
-	var versions []GoVersion
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	return supported[:min(len(supported), supportedReleases)], nil
+}
+
+// GoVersion represents a Go version from the API
+type GoVersion struct {
+	Version string   `json:"version"`
+	Stable  bool     `json:"stable"`
+	Files   []GoFile `json:"files"`
+}
+
+// GoFile represents a single downloadable file of a Go version
+type GoFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
+}
+
+// fetchAvailableVersions fetches the list of all available Go versions,
+// including older releases and pre-releases
+func fetchAvailableVersions(src releaseSource) ([]string, error) {
+	versions, err := fetchReleases(src)
+	if err != nil {
+		return nil, err
 	}
 
 	var versionStrings []string
@@ -225,3 +308,54 @@ func fetchAvailableVersions(client HTTPClient) ([]string, error) {
 
 	return versionStrings, nil
 }
+
+// findChecksum looks up the published SHA-256 checksum of an archive
+func findChecksum(v, filename string, src releaseSource, baseURL string) (string, error) {
+	versions, err := fetchFeed(src, baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	for _, version := range versions {
+		if version.Version != v {
+			continue
+		}
+		for _, file := range version.Files {
+			if file.Filename == filename && file.SHA256 != "" {
+				return file.SHA256, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no checksum published for %s", filename)
+}
+
+// fetchReleases lists the releases of the first mirror of src that
+// responds
+func fetchReleases(src releaseSource) ([]GoVersion, error) {
+	var errs []error
+	for _, m := range src.mirrorChain() {
+		releases, err := m.releases(src)
+		if err == nil {
+			return releases, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// fetchFeed fetches and decodes the release feed. Older releases
+// and pre-releases are only listed when asking for all versions.
+func fetchFeed(src releaseSource, baseURL string) ([]GoVersion, error) {
+	body, err := src.fetch(baseURL + "/?mode=json&include=all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch versions: %w", err)
+	}
+
+	var versions []GoVersion
+	if err := json.Unmarshal(body, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	return versions, nil
+}
@@ -145,7 +145,7 @@ func TestResolveVersion(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := resolveVersion(tc.input, mockClient)
+			result, err := resolveVersion(tc.input, false, releaseSource{client: mockClient})
 
 			if (err != nil) != tc.wantErr {
 				t.Errorf("resolveVersion(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
@@ -167,7 +167,7 @@ func TestResolveVersionHTTPError(t *testing.T) {
 		},
 	}
 
-	_, err := resolveVersion("1.23", mockClient)
+	_, err := resolveVersion("1.23", false, releaseSource{client: mockClient})
 	if err == nil {
 		t.Error("Expected error when HTTP request fails, got nil")
 	}
@@ -201,6 +201,84 @@ func TestIsCompleteVersion(t *testing.T) {
 	}
 }
 
+func TestIsPrereleaseVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"release candidate", "1.26rc2", true},
+		{"beta", "1.26beta1", true},
+		{"with go prefix", "go1.26rc1", true},
+		{"complete version", "1.26.0", false},
+		{"major.minor", "1.26", false},
+		{"missing number", "1.26rc", false},
+		{"unknown stage", "1.26alpha1", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := isPrereleaseVersion(tc.input)
+			if result != tc.expected {
+				t.Errorf("isPrereleaseVersion(%q) = %v, want %v", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestResolveVersionPrerelease(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if !strings.Contains(req.URL.String(), "include=all") {
+				t.Errorf("Expected request to include include=all, got: %s", req.URL.String())
+			}
+
+			jsonContent := `[
+				{"version": "go1.26rc2", "stable": false, "files": []},
+				{"version": "go1.26rc1", "stable": false, "files": []},
+				{"version": "go1.25.3", "stable": true, "files": []},
+				{"version": "go1.25.2", "stable": true, "files": []},
+				{"version": "go1.25rc1", "stable": false, "files": []}
+			]`
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(jsonContent)),
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		prerelease bool
+		expected   string
+		wantErr    bool
+	}{
+		{"explicit release candidate", "1.26rc2", false, "1.26rc2", false},
+		{"explicit beta", "go1.26beta1", false, "go1.26beta1", false},
+		{"pre-release only without opt-in", "1.26", false, "", true},
+		{"pre-release only with opt-in", "1.26", true, "go1.26rc2", false},
+		{"stable release preferred over rc", "1.25", true, "go1.25.3", false},
+		{"stable release without opt-in", "1.25", false, "go1.25.3", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := resolveVersion(tc.input, tc.prerelease, releaseSource{client: mockClient})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("resolveVersion(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+				return
+			}
+
+			if result != tc.expected {
+				t.Errorf("resolveVersion(%q) = %q, want %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
 func TestIsMajorMinorVersion(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -258,7 +336,7 @@ func TestFetchAvailableVersions(t *testing.T) {
 		},
 	}
 
-	versions, err := fetchAvailableVersions(mockClient)
+	versions, err := fetchAvailableVersions(releaseSource{client: mockClient})
 	if err != nil {
 		t.Fatalf("fetchAvailableVersions() error = %v", err)
 	}
@@ -317,7 +395,7 @@ func TestSemanticVersionSorting(t *testing.T) {
 		},
 	}
 
-	result, err := resolveVersion("1.23", mockClient)
+	result, err := resolveVersion("1.23", false, releaseSource{client: mockClient})
 	if err != nil {
 		t.Fatalf("resolveVersion() error = %v", err)
 	}
@@ -342,6 +420,13 @@ func TestCompareVersions(t *testing.T) {
 		{"1.24.1 > 1.23.10", "go1.24.1", "go1.23.10", false},
 		{"same versions", "go1.23.9", "go1.23.9", false},
 		{"without go prefix", "1.23.9", "1.23.10", true},
+		{"1.26rc1 < 1.26rc2", "go1.26rc1", "go1.26rc2", true},
+		{"1.26rc2 < 1.26.0", "go1.26rc2", "go1.26.0", true},
+		{"1.26.0 > 1.26rc2", "go1.26.0", "go1.26rc2", false},
+		{"1.26beta1 < 1.26rc1", "go1.26beta1", "go1.26rc1", true},
+		{"1.26rc1 > 1.25.9", "go1.26rc1", "go1.25.9", false},
+		{"1.20rc3 < 1.20", "go1.20rc3", "go1.20", true},
+		{"1.20 < 1.20.1", "go1.20", "go1.20.1", true},
 	}
 
 	for _, tc := range testCases {
@@ -353,3 +438,68 @@ func TestCompareVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestFindChecksum(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if !strings.Contains(req.URL.String(), "include=all") {
+				t.Errorf("Expected request to include include=all, got: %s", req.URL.String())
+			}
+
+			jsonContent := `[
+				{
+					"version": "go1.24.2",
+					"stable": true,
+					"files": [
+						{
+							"filename": "go1.24.2.linux-amd64.tar.gz",
+							"os": "linux",
+							"arch": "amd64",
+							"version": "go1.24.2",
+							"sha256": "abc123",
+							"kind": "archive"
+						},
+						{
+							"filename": "go1.24.2.src.tar.gz",
+							"version": "go1.24.2",
+							"kind": "source"
+						}
+					]
+				}
+			]`
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(jsonContent)),
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		version  string
+		filename string
+		expected string
+		wantErr  bool
+	}{
+		{"published checksum", "go1.24.2", "go1.24.2.linux-amd64.tar.gz", "abc123", false},
+		{"missing checksum", "go1.24.2", "go1.24.2.src.tar.gz", "", true},
+		{"unknown file", "go1.24.2", "go1.24.2.darwin-arm64.tar.gz", "", true},
+		{"unknown version", "go1.23.1", "go1.23.1.linux-amd64.tar.gz", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := findChecksum(tc.version, tc.filename, releaseSource{client: mockClient}, defaultDownloadURL)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("findChecksum(%q, %q) error = %v, wantErr %v", tc.version, tc.filename, err, tc.wantErr)
+				return
+			}
+
+			if result != tc.expected {
+				t.Errorf("findChecksum(%q, %q) = %q, want %q", tc.version, tc.filename, result, tc.expected)
+			}
+		})
+	}
+}
@@ -1,81 +1,230 @@
 package version
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
 	defaultInstallDir = "${HOME}/.gum/versions"
+	defaultBinDir     = "${HOME}/.gum/bin"
+	defaultCacheDir   = "${HOME}/.gum/cache"
+	// autoInstallEnvVar makes Use install missing versions by default
+	autoInstallEnvVar = "GUM_AUTO_INSTALL"
+	// Versions are extracted into a staging directory next to their
+	// final location and only renamed into place once complete
+	stagingPrefix = ".staging-"
 )
 
 // VersionManager handles Go version installation and uninstallation
 type VersionManager struct {
-	fs         FileSystem
-	httpClient HTTPClient
-	installDir string
+	fs          FileSystem
+	httpClient  HTTPClient
+	installDir  string
+	binDir      string
+	cacheDir    string
+	mirrors     []mirror
+	feedTTL     time.Duration
+	lockTimeout time.Duration
+	offline     bool
+	autoInstall bool
 }
 
-// NewManager creates a new Manager with default implementations
-func NewManager() Manager {
-	return &VersionManager{
-		fs:         OSFileSystem{},
-		httpClient: NewDefaultHTTPClient(),
-		installDir: expandPath(defaultInstallDir, OSFileSystem{}),
+// NewManager creates a new Manager with default implementations,
+// configured by config
+func NewManager(config Config) (Manager, error) {
+	httpClient, err := NewDefaultHTTPClient(config, tokenHosts(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up HTTP client: %w", err)
 	}
+
+	return &VersionManager{
+		fs:          OSFileSystem{},
+		httpClient:  httpClient,
+		installDir:  config.InstallDir,
+		binDir:      config.BinDir,
+		cacheDir:    config.CacheDir,
+		mirrors:     newMirrors(config.GoProxy, config.DownloadURLs),
+		feedTTL:     config.FeedTTL,
+		lockTimeout: config.LockTimeout,
+		offline:     config.Offline,
+		autoInstall: config.AutoInstall,
+	}, nil
 }
 
 // Install installs a specific Go version
-func (m *VersionManager) Install(v string, w io.Writer) error {
-	resolvedVersion, err := resolveVersion(v, m.httpClient)
+func (m *VersionManager) Install(ctx context.Context, v string, opts InstallOptions, w io.Writer) error {
+	src := m.source(ctx, w)
+	resolved, err := m.resolveInstall(ctx, v, opts, src, w)
 	if err != nil {
-		return fmt.Errorf("failed to resolve version %s: %w", v, err)
+		return err
+	}
+	return m.install(ctx, src, resolved, w)
+}
+
+// resolveInstall resolves the version, alias or constraint v asks to install,
+// returning it normalised
+func (m *VersionManager) resolveInstall(ctx context.Context, v string, opts InstallOptions, src releaseSource, w io.Writer) (string, error) {
+	if strings.TrimSpace(v) == "" {
+		return "", fmt.Errorf("no version provided")
+	}
+
+	v, err := m.resolveSpec(ctx, v, resolveOptions{prerelease: opts.Prerelease}, src, w)
+	if err != nil {
+		return "", err
+	}
+
+	resolvedVersion, err := resolveVersion(v, opts.Prerelease, src)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve version %s: %w", v, err)
 	}
 
 	if resolvedVersion != v {
 		fmt.Fprintf(w, "Resolved %s to %s\n", v, resolvedVersion)
 	}
 
-	v = normaliseVersion(resolvedVersion)
+	if isPrereleaseVersion(resolvedVersion) {
+		fmt.Fprintf(w, "Note: %s is a pre-release version\n", resolvedVersion)
+	}
+
+	return normaliseVersion(resolvedVersion), nil
+}
+
+// install installs the resolved version v, fetching releases from src
+func (m *VersionManager) install(ctx context.Context, src releaseSource, v string, w io.Writer) error {
 	versionDir := filepath.Join(m.installDir, v)
 
+	// Another gum may be installing the same version, in which case
+	// this waits for it and finds the version installed
+	unlock, err := m.lock(ctx, v, w)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Check if already installed
 	if _, err := m.fs.Stat(versionDir); err == nil {
-		fmt.Fprintf(w, "Go %s is already installed at %s\n", v, versionDir)
-		return nil
-	}
+		if m.isComplete(versionDir) {
+			fmt.Fprintf(w, "Go %s is already installed at %s\n", v, versionDir)
+			return nil
+		}
 
-	// Get download url based on version and architecture
-	downloadURL, err := getDownloadURL(v)
-	if err != nil {
-		return err
+		fmt.Fprintf(w, "Found incomplete install of Go %s, reinstalling\n", v)
+		if err := m.fs.RemoveAll(versionDir); err != nil {
+			return fmt.Errorf("failed to remove incomplete install: %w", err)
+		}
 	}
 
 	if err := m.fs.MkdirAll(m.installDir, 0755); err != nil {
 		return fmt.Errorf("failed to create installation directory: %w", err)
 	}
 
-	fmt.Fprintf(w, "Downloading %s...\n", downloadURL)
-	if err := downloadAndExtract(downloadURL, versionDir, w, m.httpClient); err != nil {
-		m.fs.RemoveAll(versionDir)
+	// Remove leftovers of an earlier interrupted install
+	stagingDir := filepath.Join(m.installDir, stagingPrefix+v)
+	if err := m.fs.RemoveAll(stagingDir); err != nil {
+		return fmt.Errorf("failed to remove stale staging directory: %w", err)
+	}
+
+	if err := m.download(ctx, src, v, stagingDir, w); err != nil {
 		return err
 	}
 
+	if !m.isComplete(stagingDir) {
+		m.fs.RemoveAll(stagingDir)
+		return fmt.Errorf("Go binary not found in extracted archive")
+	}
+
+	if err := m.fs.Rename(stagingDir, versionDir); err != nil {
+		m.fs.RemoveAll(stagingDir)
+		return fmt.Errorf("failed to move Go %s into place: %w", v, err)
+	}
+
 	fmt.Fprintf(w, "Successfully installed Go %s at %s\n", v, versionDir)
 	return nil
 }
 
+// download downloads and extracts Go version v into destDir, trying
+// each mirror in turn until one succeeds
+func (m *VersionManager) download(ctx context.Context, src releaseSource, v, destDir string, w io.Writer) error {
+	chain := src.mirrorChain()
+
+	var errs []error
+	for i, mirror := range chain {
+		err := m.downloadFrom(ctx, src, mirror, v, destDir, w)
+		if err == nil {
+			return nil
+		}
+
+		m.fs.RemoveAll(destDir)
+		if ctx.Err() != nil {
+			return err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", mirror, err))
+		if i < len(chain)-1 {
+			fmt.Fprintf(w, "Download from %s failed: %v\nTrying %s instead\n", mirror, err, chain[i+1])
+		}
+	}
+
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return fmt.Errorf("all mirrors failed: %w", errors.Join(errs...))
+}
+
+func (m *VersionManager) downloadFrom(ctx context.Context, src releaseSource, mirror mirror, v, destDir string, w io.Writer) error {
+	fmt.Fprintf(w, "Downloading Go %s from %s...\n", v, mirror)
+	a, err := mirror.archive(v, src)
+	if err != nil {
+		return err
+	}
+
+	if m.offline {
+		cachePath := cachedArchivePath(m.cacheDir, a)
+		if _, err := m.fs.Stat(cachePath); cachePath == "" || err != nil {
+			return fmt.Errorf("%s is not in the download cache, which is all that can be used offline", path.Base(a.url))
+		}
+	}
+
+	return downloadAndExtract(ctx, a, destDir, m.cacheDir, w, m.httpClient)
+}
+
+// source returns where releases are fetched from, until ctx is
+// cancelled. Warnings about using cached release lists are written to w.
+func (m *VersionManager) source(ctx context.Context, w io.Writer) releaseSource {
+	cache := feedCache{ttl: m.feedTTL, offline: m.offline, w: w}
+	if m.cacheDir != "" {
+		cache.dir = filepath.Join(m.cacheDir, feedCacheDir)
+	}
+	return releaseSource{ctx: ctx, client: m.httpClient, mirrors: m.mirrors, cache: cache, memo: &fetchMemo{}}
+}
+
 // Uninstall removes a specific Go version
-func (m *VersionManager) Uninstall(v string, w io.Writer) error {
+func (m *VersionManager) Uninstall(ctx context.Context, v string, w io.Writer) error {
+	if strings.TrimSpace(v) == "" {
+		return fmt.Errorf("no version provided")
+	}
+
 	v = normaliseVersion(v)
 	versionDir := filepath.Join(m.installDir, v)
 
 	fmt.Fprintf(w, "Uninstalling Go version %s\n", v)
 
+	unlock, err := m.lock(ctx, v, w)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Check if the version is installed
 	if _, err := m.fs.Stat(versionDir); os.IsNotExist(err) {
 		fmt.Fprintf(w, "Go %s is not installed at %s\n", v, versionDir)
@@ -91,75 +240,132 @@ func (m *VersionManager) Uninstall(v string, w io.Writer) error {
 	return nil
 }
 
-// Use creates a symlink to make the specified Go version active
-func (m *VersionManager) Use(v string, w io.Writer) error {
+// Use links the binaries of the specified Go version into ~/.gum/bin
+// to make it active. The version may also be an alias such as latest or
+// go.mod. A missing version is installed first if requested through opts
+// or GUM_AUTO_INSTALL
+func (m *VersionManager) Use(ctx context.Context, v string, opts UseOptions, w io.Writer) error {
 	if v == "" {
-		goModVersion, err := detectVersionInGoMod(m.fs)
+		requested, err := detectVersion(m.fs)
+		if err != nil {
+			return fmt.Errorf("failed to detect Go version: %w", err)
+		}
+
+		v, err = m.resolveRequestedVersion(ctx, requested, w)
 		if err != nil {
-			return fmt.Errorf("Failed to detect version in go.mod: %w", err)
+			return err
+		}
+	} else {
+		var err error
+		v, err = m.resolveSpec(ctx, v, resolveOptions{preferInstalled: true}, m.source(ctx, w), w)
+		if err != nil {
+			return err
 		}
-		v = goModVersion
-		fmt.Fprintf(w, "Detected Go %s from go.mod\n", v)
 	}
 
 	v = normaliseVersion(v)
 	versionDir := filepath.Join(m.installDir, v)
 
 	// Check if version is installed
-	if _, err := m.fs.Stat(versionDir); os.IsNotExist(err) {
-		return fmt.Errorf("Go %s is not installed. Use 'gum install %s' first", v, v)
+	if !m.isComplete(versionDir) {
+		if !opts.Install && !m.autoInstall {
+			return fmt.Errorf("Go %s is not installed. Use 'gum install %s' first, or pass --install", v, v)
+		}
+
+		installed, err := m.installMissing(ctx, v, w)
+		if err != nil {
+			return err
+		}
+		v = installed
+		versionDir = filepath.Join(m.installDir, v)
+	}
+
+	// Keep the version from being removed while it is made active, and
+	// other gums from changing the links at the same time
+	unlock, err := m.lock(ctx, v, w)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	// Get user home directory
-	home, err := m.fs.UserHomeDir()
+	unlockActive, err := m.lock(ctx, activeLock, w)
 	if err != nil {
-		return fmt.Errorf("failed to get user home directory: %w", err)
+		return err
 	}
+	defer unlockActive()
 
 	// Create .gum/bin directory if it doesn't already exist
 	// This is where active go versions will be linked from
-	binDir := filepath.Join(home, ".gum", "bin")
+	binDir := m.binDir
 	if err := m.fs.MkdirAll(binDir, 0755); err != nil {
 		return fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
-	// Find target go binary for the symlink
-	srcPath := filepath.Join(versionDir, "bin", "go")
-	if _, err := m.fs.Stat(srcPath); os.IsNotExist(err) {
+	// Every binary of the version is linked, not just go
+	srcDir := filepath.Join(versionDir, "bin")
+	tools, err := m.toolchainBinaries(srcDir)
+	if err != nil || !slices.Contains(tools, "go") {
 		return fmt.Errorf("Go binary not found in %s", versionDir)
 	}
 
-	// Path to the symlink
-	linkPath := filepath.Join(binDir, "go")
-
-	// Check if symlink already exists and remove it
-	if _, err := m.fs.Stat(linkPath); err == nil {
-		// Try reading the current link to see where it points
-		currentTarget, err := m.fs.ReadLink(linkPath)
-		if err == nil && filepath.Clean(currentTarget) == filepath.Clean(srcPath) {
-			// Symlink already points to requested version
-			fmt.Fprintf(w, "Go %s is already the active version\n", v)
-			return nil
+	// With shims, the version only becomes the fallback for directories
+	// that do not request a version of their own
+	if m.shimsEnabled() {
+		gumPath, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate gum executable: %w", err)
 		}
-
-		// Symlink point to wrong version so we remove it
-		if err := m.fs.Remove(linkPath); err != nil {
-			return fmt.Errorf("failed to update Go version: %w", err)
+		if err := m.setDefaultVersion(v, gumPath); err != nil {
+			return fmt.Errorf("failed to set Go %s as default: %w", v, err)
 		}
+		fmt.Fprintf(w, "Successfully set Go %s as the default version\n", v)
+		return nil
 	}
 
-	// Create new symlink pointing to requested version
-	if err := m.fs.Symlink(srcPath, linkPath); err != nil {
+	changed, err := m.linkToolchain(binDir, srcDir, tools)
+	if err != nil {
 		return fmt.Errorf("failed to set Go %s as active: %w", v, err)
 	}
 
+	if !changed {
+		// Links already point to requested version
+		fmt.Fprintf(w, "Go %s is already the active version\n", v)
+		return nil
+	}
+
 	fmt.Fprintf(w, "Successfully set Go %s as the active version\n", v)
 
 	return nil
 }
 
-func (m *VersionManager) List(w io.Writer) error {
-	entries, err := os.ReadDir(m.installDir)
+// Pin writes a .gum-version file to the current directory, so that
+// 'gum use' without a version picks v for this directory and below
+func (m *VersionManager) Pin(ctx context.Context, v string, w io.Writer) error {
+	v = strings.TrimPrefix(v, "go")
+	if !isCompleteVersion(v) && !isMajorMinorVersion(v) && !isPrereleaseVersion(v) {
+		return fmt.Errorf("invalid Go version %s", v)
+	}
+
+	cwd, err := m.fs.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	pinPath := filepath.Join(cwd, pinFile)
+	if err := m.fs.WriteFile(pinPath, []byte(v+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", pinPath, err)
+	}
+
+	fmt.Fprintf(w, "Pinned Go %s in %s\n", v, pinPath)
+	return nil
+}
+
+func (m *VersionManager) List(ctx context.Context, w io.Writer) error {
+	if err := m.cleanIncompleteInstalls(w); err != nil {
+		return err
+	}
+
+	entries, err := m.fs.ReadDir(m.installDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			fmt.Fprintln(w, "No Go versions installed yet")
@@ -169,84 +375,346 @@ func (m *VersionManager) List(w io.Writer) error {
 		return fmt.Errorf("failed to read versions directory: %w", err)
 	}
 
-	if len(entries) == 0 {
+	var versions []string
+	for _, entry := range entries {
+		// Staging directories of installs still running are kept by
+		// the cleanup, but are not installed yet
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), stagingPrefix) {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	if len(versions) == 0 {
 		fmt.Fprintln(w, "No Go versions installed yet")
 		return nil
 	}
 
 	// Find active version, if any
-	activeVersion := ""
-	home, _ := m.fs.UserHomeDir()
-	binDir := filepath.Join(home, ".gum", "bin")
-	// Full path to the 'go' command symlink
-	linkPath := filepath.Join(binDir, "go")
-
-	resolvedPath, err := filepath.EvalSymlinks(linkPath)
-	if err == nil {
-		// The version binary is stored two folders deep in the version folder,
-		// so we need to go two folders up to get the version
-		versionDir := filepath.Dir(filepath.Dir(resolvedPath))
-		// Then we can get the version from the folder name
-		activeVersion = filepath.Base(versionDir)
+	activeVersion := m.activeVersion(m.binDir)
+	activeLabel := "active"
+	if m.shimsEnabled() {
+		activeVersion, _ = m.defaultVersion()
+		activeLabel = "default"
 	}
 
 	fmt.Fprintln(w, "Installed Go versions:")
 
+	for _, version := range versions {
+		if version == activeVersion {
+			fmt.Fprintf(w, "* %s (%s)\n", version, activeLabel)
+		} else {
+			fmt.Fprintf(w, "  %s\n", version)
+		}
+	}
+	return nil
+}
+
+// toolchainBinaries lists the names of the binaries in srcDir
+func (m *VersionManager) toolchainBinaries(srcDir string) ([]string, error) {
+	entries, err := m.fs.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []string
 	for _, entry := range entries {
 		if !entry.IsDir() {
+			tools = append(tools, entry.Name())
+		}
+	}
+	return tools, nil
+}
+
+// linkToolchain points a symlink in binDir at each of the tools in srcDir,
+// and removes links to binaries of other versions that srcDir does not
+// provide. Anything in binDir not linking into installDir, like gum itself,
+// is left alone. Returns whether any link had to change.
+func (m *VersionManager) linkToolchain(binDir, srcDir string, tools []string) (bool, error) {
+	changed := false
+
+	existing, err := m.fs.ReadDir(binDir)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	for _, entry := range existing {
+		if slices.Contains(tools, entry.Name()) {
 			continue
 		}
 
-		version := entry.Name()
-		if version == activeVersion {
-			fmt.Fprintf(w, "* %s (active)\n", version)
+		linkPath := filepath.Join(binDir, entry.Name())
+		target, err := m.fs.ReadLink(linkPath)
+		if err != nil || !isWithin(m.installDir, target) {
+			continue
+		}
+
+		if err := m.fs.Remove(linkPath); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	for _, tool := range tools {
+		srcPath := filepath.Join(srcDir, tool)
+		linkPath := filepath.Join(binDir, tool)
+
+		// Try reading the current link to see where it points
+		currentTarget, err := m.fs.ReadLink(linkPath)
+		if err == nil && filepath.Clean(currentTarget) == filepath.Clean(srcPath) {
+			continue
+		}
+
+		// Link points to another version, or is dangling, so we remove it
+		if _, statErr := m.fs.Stat(linkPath); err == nil || statErr == nil {
+			if err := m.fs.Remove(linkPath); err != nil {
+				return changed, err
+			}
+		}
+
+		if err := m.fs.Symlink(srcPath, linkPath); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// activeVersion returns the version the links in binDir point to, if any
+func (m *VersionManager) activeVersion(binDir string) string {
+	entries, err := m.fs.ReadDir(binDir)
+	if err != nil {
+		return ""
+	}
+
+	// Check the go link first, then any other tool
+	names := []string{"go"}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	for _, name := range names {
+		target, err := m.fs.ReadLink(filepath.Join(binDir, name))
+		if err != nil || !isWithin(m.installDir, target) {
+			continue
+		}
+
+		rel, err := filepath.Rel(m.installDir, target)
+		if err != nil || rel == "." {
+			continue
+		}
+		return strings.Split(rel, string(filepath.Separator))[0]
+	}
+
+	return ""
+}
+
+// installMissing resolves v and installs it for Use, returning the
+// normalised version that ended up installed
+func (m *VersionManager) installMissing(ctx context.Context, v string, w io.Writer) (string, error) {
+	resolvedVersion, err := resolveVersion(v, false, m.source(ctx, w))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve version %s: %w", v, err)
+	}
+	resolvedVersion = normaliseVersion(resolvedVersion)
+
+	if m.isComplete(filepath.Join(m.installDir, resolvedVersion)) {
+		fmt.Fprintf(w, "Resolved %s to installed %s\n", v, resolvedVersion)
+		return resolvedVersion, nil
+	}
+
+	fmt.Fprintf(w, "Go %s is not installed, installing it first\n", resolvedVersion)
+	if err := m.Install(ctx, resolvedVersion, InstallOptions{}, w); err != nil {
+		return "", fmt.Errorf("failed to install Go %s: %w", resolvedVersion, err)
+	}
+
+	return resolvedVersion, nil
+}
+
+// ListRemote lists the Go versions available for download, grouped by
+// minor release. A filter such as "1.23" limits the list to matching versions.
+func (m *VersionManager) ListRemote(ctx context.Context, filter string, w io.Writer) error {
+	releases, err := fetchReleases(m.source(ctx, w))
+	if err != nil {
+		return fmt.Errorf("failed to fetch available versions: %w", err)
+	}
+
+	var matching []GoVersion
+	for _, release := range releases {
+		if filter == "" || matchesVersionFilter(release.Version, filter) {
+			matching = append(matching, release)
+		}
+	}
+
+	if len(matching) == 0 {
+		fmt.Fprintf(w, "No Go versions available matching %s\n", filter)
+		return nil
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return compareVersions(matching[j].Version, matching[i].Version) // reverse for newest first
+	})
+
+	fmt.Fprintln(w, "Available Go versions:")
+
+	currentGroup := ""
+	for _, release := range matching {
+		parts, _ := parseVersion(release.Version)
+		group := fmt.Sprintf("go%d.%d", parts.major, parts.minor)
+		if group != currentGroup {
+			fmt.Fprintf(w, "\n%s\n", group)
+			currentGroup = group
+		}
+
+		var labels []string
+		if !release.Stable {
+			labels = append(labels, "unstable")
+		}
+
+		marker := " "
+		if m.isComplete(filepath.Join(m.installDir, release.Version)) {
+			marker = "*"
+			labels = append(labels, "installed")
+		}
+
+		if len(labels) > 0 {
+			fmt.Fprintf(w, "%s %-12s (%s)\n", marker, release.Version, strings.Join(labels, ", "))
 		} else {
-			fmt.Fprintf(w, "  %s\n", version)
+			fmt.Fprintf(w, "%s %s\n", marker, release.Version)
 		}
 	}
+
 	return nil
 }
 
-// Utility function to expand paths using the filesystem
-func expandPath(path string, fs FileSystem) string {
-	if strings.HasPrefix(path, "${HOME}") {
-		home, err := fs.UserHomeDir()
-		if err == nil {
-			return strings.Replace(path, "${HOME}", home, 1)
+// matchesVersionFilter reports whether v belongs to the release series
+// named by filter, so "1.23" matches go1.23.4 and go1.23rc1 but not go1.2
+func matchesVersionFilter(v, filter string) bool {
+	prefix := normaliseVersion(filter)
+	if v == prefix {
+		return true
+	}
+
+	for _, sep := range []string{".", "rc", "beta"} {
+		if strings.HasPrefix(v, prefix+sep) {
+			return true
 		}
 	}
-	return path
+	return false
+}
+
+// isComplete reports whether versionDir holds a usable Go installation
+func (m *VersionManager) isComplete(versionDir string) bool {
+	_, err := m.fs.Stat(filepath.Join(versionDir, "bin", "go"))
+	return err == nil
+}
+
+// cleanIncompleteInstalls removes staging directories left behind by
+// interrupted installs and version directories missing the Go binary.
+// Directories not named after a Go version are never touched.
+func (m *VersionManager) cleanIncompleteInstalls(w io.Writer) error {
+	entries, err := m.fs.ReadDir(m.installDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read versions directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !isVersionDirName(entry.Name()) {
+			continue
+		}
+
+		// Leave versions alone that another gum is installing right now,
+		// and keep others from starting to while removing them
+		unlock := m.tryLock(strings.TrimPrefix(entry.Name(), stagingPrefix))
+		if unlock == nil {
+			continue
+		}
+		err := m.removeIncompleteInstall(entry.Name(), w)
+		unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isVersionDirName reports whether name is one gum gives the directory
+// of a version or its staging directory. Anything else in the install
+// directory does not belong to gum and is left alone.
+func isVersionDirName(name string) bool {
+	name = strings.TrimPrefix(name, stagingPrefix)
+	if !strings.HasPrefix(name, "go") {
+		return false
+	}
+	_, ok := parseVersion(name)
+	return ok
 }
 
-// detectVersionInGoMod read go.mod in current directory
-// and tries to extract the Go version
-func detectVersionInGoMod(fs FileSystem) (string, error) {
+// removeIncompleteInstall removes the entry name of the install
+// directory if it is a staging directory or an incomplete install.
+// The caller holds the lock on its version.
+func (m *VersionManager) removeIncompleteInstall(name string, w io.Writer) error {
+	path := filepath.Join(m.installDir, name)
+
+	if strings.HasPrefix(name, stagingPrefix) {
+		if err := m.fs.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove stale staging directory: %w", err)
+		}
+		return nil
+	}
 
-	if _, err := fs.Stat("go.mod"); os.IsNotExist(err) {
-		return "", nil
+	if !m.isComplete(path) {
+		fmt.Fprintf(w, "Removing incomplete install of Go %s\n", name)
+		if err := m.fs.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove incomplete install: %w", err)
+		}
 	}
 
-	modFile, err := os.Open("go.mod")
+	return nil
+}
+
+// installedVersions returns the complete installed versions, newest first
+func (m *VersionManager) installedVersions() ([]string, error) {
+	entries, err := m.fs.ReadDir(m.installDir)
 	if err != nil {
-		return "", fmt.Errorf("could not open go.mod file: %w", err)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read versions directory: %w", err)
 	}
-	defer modFile.Close()
 
-	// Read the file line by line to find the go version
-	scanner := bufio.NewScanner(modFile)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		// Look for lines that start with 'go'
-		if strings.HasPrefix(line, "go ") {
-			version := strings.TrimPrefix(line, "go ")
-			// Assume the first line we find that starts with 'go'
-			// Is the one that defines the Go version
-			if version == "" {
-				return "", fmt.Errorf("invalid Go version format in go.mod")
-			}
-			return version, nil
+	var versions []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), stagingPrefix) {
+			continue
+		}
+		if m.isComplete(filepath.Join(m.installDir, entry.Name())) {
+			versions = append(versions, entry.Name())
 		}
 	}
 
-	return "", fmt.Errorf("no Go version found in go.mod")
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[j], versions[i]) // reverse for newest first
+	})
+
+	return versions, nil
+}
+
+// Utility function to expand paths using the filesystem
+// Both ${HOME} and ~ refer to the home directory
+func expandPath(path string, fs FileSystem) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = "${HOME}" + path[1:]
+	}
+	if strings.HasPrefix(path, "${HOME}") {
+		home, err := fs.UserHomeDir()
+		if err == nil {
+			return strings.Replace(path, "${HOME}", home, 1)
+		}
+	}
+	return path
 }